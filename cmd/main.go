@@ -1,6 +1,7 @@
 package main
 
 import (
+	"expvar"
 	"log"
 	"log/slog"
 	"net/http"
@@ -19,6 +20,7 @@ import (
 	_ "net/http/pprof"
 
 	assetcmd "github.com/adm87/deepdown/cmd/assets"
+	physicscmd "github.com/adm87/deepdown/cmd/physics"
 )
 
 // TASK: Setup build tags
@@ -45,22 +47,31 @@ func main() {
 
 			assets.RegisterFilesystem("assets", os.DirFS(path.Join(root, "data", "assets")))
 			assets.RegisterFilesystem("embedded", data.EmbeddedFS)
+			assets.RegisterFilesystem("data", os.DirFS(path.Join(root, "data")))
 
 			assets.RegisterImporters(ctx)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx.Logger().Info("Starting Deepdown...")
 
+			g := game.NewGame(ctx)
+
 			if profile {
 				ctx.Logger().Info("Profiling enabled")
 
+				g.SetCollectStats(true)
+				expvar.Publish("physics", expvar.Func(func() any {
+					return g.PhysicsStats()
+				}))
+
 				go func() {
 					log.Println("Profiling server at http://localhost:6060/debug/pprof/")
+					log.Println("Physics stats at http://localhost:6060/debug/vars")
 					log.Println(http.ListenAndServe("localhost:6060", nil))
 				}()
 			}
 
-			return ebiten.RunGame(game.NewGame(ctx))
+			return ebiten.RunGame(g)
 		},
 	}
 
@@ -68,6 +79,8 @@ func main() {
 	cmd.Flags().BoolVar(&profile, "profile", false, "Enable profiling")
 
 	cmd.AddCommand(assetcmd.GenerateHandles(ctx))
+	cmd.AddCommand(physicscmd.BenchmarkBroadphase(ctx))
+	cmd.AddCommand(physicscmd.Simulate(ctx))
 
 	if err := cmd.ExecuteContext(ctx.Ctx()); err != nil {
 		ctx.Logger().Error("Command execution failed", slog.String("error", err.Error()))
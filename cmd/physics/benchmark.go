@@ -0,0 +1,155 @@
+package physics
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adm87/deepdown/data"
+	"github.com/adm87/deepdown/scripts/assets"
+	"github.com/adm87/deepdown/scripts/deepdown"
+	"github.com/adm87/deepdown/scripts/level"
+	"github.com/adm87/deepdown/scripts/physics"
+	"github.com/spf13/cobra"
+)
+
+const (
+	benchmarkBodySize  = 4.0
+	benchmarkQuerySize = 16.0
+	benchmarkFixedStep = 1.0 / 60.0
+)
+
+type benchmarkResult struct {
+	tilemap    assets.AssetHandle
+	broadphase physics.BroadphaseType
+	build      time.Duration
+	step       time.Duration
+	query      time.Duration
+}
+
+func BenchmarkBroadphase(ctx deepdown.Context) *cobra.Command {
+	var (
+		steps   int
+		bodies  int
+		queries int
+		seed    int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "benchmark-broadphase [tilemap...]",
+		Short: "Benchmark the physics broadphases against tilemaps",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			handles := []assets.AssetHandle{data.GymCollision}
+			if len(args) > 0 {
+				handles = handles[:0]
+				for _, arg := range args {
+					handles = append(handles, assets.AssetHandle(arg))
+				}
+			}
+
+			if err := loadProject(); err != nil {
+				ctx.Logger().Error("error", slog.Any("err", err))
+				return err
+			}
+
+			results := make([]benchmarkResult, 0, len(handles)*3)
+			for _, handle := range handles {
+				tm, err := loadTilemap(handle)
+				if err != nil {
+					ctx.Logger().Error("error", slog.Any("err", err))
+					return err
+				}
+
+				for bt := physics.BroadphaseGrid; bt.IsValid(); bt++ {
+					result, err := benchmarkTilemap(ctx, tm, bt, steps, bodies, queries, seed)
+					if err != nil {
+						ctx.Logger().Error("error", slog.Any("err", err))
+						return err
+					}
+					result.tilemap = handle
+					results = append(results, result)
+				}
+			}
+
+			printBenchmarkResults(results, steps, queries)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&steps, "steps", 600, "Number of fixed steps simulated per broadphase")
+	cmd.Flags().IntVar(&bodies, "bodies", 100, "Number of dynamic bodies spawned over the map")
+	cmd.Flags().IntVar(&queries, "queries", 10000, "Number of random region queries per broadphase")
+	cmd.Flags().Int64Var(&seed, "seed", 1, "Seed used to place bodies and queries")
+
+	return cmd
+}
+
+func benchmarkTilemap(ctx deepdown.Context, tm *assets.Tilemap, bt physics.BroadphaseType, steps, bodies, queries int, seed int64) (benchmarkResult, error) {
+	result := benchmarkResult{broadphase: bt}
+
+	config, err := level.PhysicsConfig(tm)
+	if err != nil {
+		return result, err
+	}
+	config.Broadphase = bt
+
+	width := float32(tm.Width * tm.TileWidth)
+	height := float32(tm.Height * tm.TileHeight)
+
+	lvl := level.NewLevel(ctx, width, height)
+
+	start := time.Now()
+	if err := lvl.SetTmxWithConfig(tm, config); err != nil {
+		return result, err
+	}
+	result.build = time.Since(start)
+
+	world := lvl.World()
+	rng := rand.New(rand.NewSource(seed))
+
+	for range bodies {
+		x := rng.Float32() * (width - benchmarkBodySize)
+		y := rng.Float32() * (height - benchmarkBodySize)
+
+		body := world.Pool().GetBoxCollider(x, y, benchmarkBodySize, benchmarkBodySize)
+		body.Info().State = physics.ColliderStateDynamic
+		world.AddCollider(body)
+	}
+
+	// The whole map is simulated so every body moves through the broadphase each step
+	start = time.Now()
+	for range steps {
+		world.Update(benchmarkFixedStep, 0, 0, width, height)
+	}
+	result.step = time.Since(start)
+
+	start = time.Now()
+	for range queries {
+		x := rng.Float32() * (width - benchmarkQuerySize)
+		y := rng.Float32() * (height - benchmarkQuerySize)
+
+		world.QueryStatic(x, y, x+benchmarkQuerySize, y+benchmarkQuerySize)
+		world.QueryBody(x, y, x+benchmarkQuerySize, y+benchmarkQuerySize)
+	}
+	result.query = time.Since(start)
+
+	return result, nil
+}
+
+func printBenchmarkResults(results []benchmarkResult, steps, queries int) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TILEMAP\tBROADPHASE\tBUILD\tSTEP\tQUERY")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			r.tilemap,
+			r.broadphase,
+			r.build,
+			r.step/time.Duration(max(steps, 1)),
+			r.query/time.Duration(max(queries, 1)),
+		)
+	}
+	w.Flush()
+}
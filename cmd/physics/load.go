@@ -0,0 +1,42 @@
+package physics
+
+import (
+	"fmt"
+
+	"github.com/adm87/deepdown/data"
+	"github.com/adm87/deepdown/scripts/assets"
+	"github.com/adm87/deepdown/scripts/level"
+)
+
+// loadProject loads the Tiled project and applies the collision layers it declares.
+func loadProject() error {
+	if err := assets.Load(data.Project); err != nil {
+		return err
+	}
+
+	project, ok := assets.Get[*assets.Project](data.Project)
+	if !ok {
+		return fmt.Errorf("asset %s is not a Tiled project", data.Project)
+	}
+	return level.LoadCollisionLayers(project)
+}
+
+// loadTilemap loads a tilemap and the tilesets it references.
+func loadTilemap(handle assets.AssetHandle) (*assets.Tilemap, error) {
+	if err := assets.Load(handle); err != nil {
+		return nil, err
+	}
+
+	tm, ok := assets.Get[*assets.Tilemap](handle)
+	if !ok {
+		return nil, fmt.Errorf("asset %s is not a tilemap", handle)
+	}
+
+	for i := range tm.Tilesets {
+		if err := assets.Load(tm.Tilesets[i].Source); err != nil {
+			return nil, err
+		}
+	}
+
+	return tm, nil
+}
@@ -0,0 +1,142 @@
+package physics
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/adm87/deepdown/data"
+	"github.com/adm87/deepdown/scripts/assets"
+	"github.com/adm87/deepdown/scripts/deepdown"
+	"github.com/adm87/deepdown/scripts/game"
+	"github.com/adm87/deepdown/scripts/input"
+	"github.com/adm87/deepdown/scripts/input/actions"
+	"github.com/adm87/deepdown/scripts/level"
+	"github.com/spf13/cobra"
+)
+
+// maxReportedDiffs limits how many trace differences are logged by --compare.
+const maxReportedDiffs = 10
+
+func Simulate(ctx deepdown.Context) *cobra.Command {
+	var (
+		steps     int
+		dt        float64
+		script    string
+		output    string
+		compare   string
+		tolerance float64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "simulate [tilemap]",
+		Short: "Run a level's physics without a window and trace its bodies",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			handle := data.GymCollision
+			if len(args) > 0 {
+				handle = assets.AssetHandle(args[0])
+			}
+
+			keys := &inputScript{}
+			if script != "" {
+				var err error
+				if keys, err = readInputScript(script); err != nil {
+					ctx.Logger().Error("error", slog.Any("err", err))
+					return err
+				}
+			}
+
+			trace, err := simulate(ctx, handle, keys, steps, dt)
+			if err != nil {
+				ctx.Logger().Error("error", slog.Any("err", err))
+				return err
+			}
+
+			switch {
+			case output != "":
+				if err := writeTrace(output, trace); err != nil {
+					ctx.Logger().Error("error", slog.Any("err", err))
+					return err
+				}
+				ctx.Logger().Info("Trace written", slog.String("output", output), slog.Int("records", len(trace)))
+			case compare == "":
+				if err := writeTraceCSV(os.Stdout, trace); err != nil {
+					return err
+				}
+			}
+
+			if compare == "" {
+				return nil
+			}
+
+			golden, err := readTrace(compare)
+			if err != nil {
+				ctx.Logger().Error("error", slog.Any("err", err))
+				return err
+			}
+
+			diffs := compareTraces(trace, golden, tolerance)
+			for i, diff := range diffs {
+				if i == maxReportedDiffs {
+					ctx.Logger().Error("Further differences omitted", slog.Int("omitted", len(diffs)-i))
+					break
+				}
+				ctx.Logger().Error(diff)
+			}
+			if len(diffs) > 0 {
+				return fmt.Errorf("trace differs from %s in %d records", compare, len(diffs))
+			}
+
+			ctx.Logger().Info("Trace matches golden trace", slog.String("golden", compare))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&steps, "steps", "n", 600, "Number of fixed steps to simulate")
+	cmd.Flags().Float64Var(&dt, "dt", 1.0/60.0, "Duration of a fixed step in seconds")
+	cmd.Flags().StringVarP(&script, "input", "i", "", "Input script of held actions by step")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Trace output file, JSON if it ends in .json and CSV otherwise (defaults to CSV on stdout)")
+	cmd.Flags().StringVar(&compare, "compare", "", "Golden trace to compare against")
+	cmd.Flags().Float64Var(&tolerance, "tolerance", 1e-4, "Largest position or velocity difference accepted by --compare")
+
+	return cmd
+}
+
+// simulate runs a level for a number of fixed steps, one input update per step, and records a trace of its bodies.
+func simulate(ctx deepdown.Context, handle assets.AssetHandle, script *inputScript, steps int, dt float64) ([]TraceRecord, error) {
+	if err := loadProject(); err != nil {
+		return nil, err
+	}
+
+	tm, err := loadTilemap(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	input.SetKeySource(script.Source)
+	defer input.SetKeySource(nil)
+	actions.RegisterBindings()
+
+	width := float32(game.TargetWidth) * float32(game.Scale)
+	height := float32(game.TargetHeight) * float32(game.Scale)
+
+	lvl := level.NewLevel(ctx, width, height)
+	if err := lvl.SetTmx(tm); err != nil {
+		return nil, err
+	}
+
+	var trace []TraceRecord
+	for step := range steps {
+		script.step = step
+
+		input.Update(dt)
+		lvl.Update(dt)
+		lvl.FixedUpdate(dt)
+		lvl.LateUpdate(dt)
+
+		trace = appendTrace(trace, step, lvl.World().Snapshot())
+	}
+
+	return trace, nil
+}
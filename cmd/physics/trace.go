@@ -0,0 +1,288 @@
+package physics
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/adm87/deepdown/scripts/input"
+	"github.com/adm87/deepdown/scripts/input/actions"
+	"github.com/adm87/deepdown/scripts/physics"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ========== Trace ==========
+
+// TraceRecord is the state of one body or kinematic collider after a fixed step.
+type TraceRecord struct {
+	Step     int     `json:"step"`
+	ID       uint32  `json:"id"`
+	Kind     string  `json:"kind"`
+	X        float32 `json:"x"`
+	Y        float32 `json:"y"`
+	VX       float32 `json:"vx"`
+	VY       float32 `json:"vy"`
+	OnGround bool    `json:"onGround"`
+}
+
+var traceHeader = []string{"step", "id", "kind", "x", "y", "vx", "vy", "on_ground"}
+
+// appendTrace appends the state of every body and kinematic collider in a snapshot.
+func appendTrace(trace []TraceRecord, step int, snapshot *physics.Snapshot) []TraceRecord {
+	for i := range snapshot.Bodies {
+		b := &snapshot.Bodies[i]
+		trace = append(trace, TraceRecord{
+			Step:     step,
+			ID:       b.ID,
+			Kind:     "body",
+			X:        b.Position[0],
+			Y:        b.Position[1],
+			VX:       b.Velocity[0],
+			VY:       b.Velocity[1],
+			OnGround: b.OnGround,
+		})
+	}
+	for i := range snapshot.Kinematics {
+		k := &snapshot.Kinematics[i]
+		trace = append(trace, TraceRecord{
+			Step: step,
+			ID:   k.ID,
+			Kind: "kinematic",
+			X:    k.Position[0],
+			Y:    k.Position[1],
+			VX:   k.Velocity[0],
+			VY:   k.Velocity[1],
+		})
+	}
+	return trace
+}
+
+// isJSON reports whether a trace path uses the JSON format, CSV being the default.
+func isJSON(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+func writeTrace(path string, trace []TraceRecord) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if isJSON(path) {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(trace)
+	}
+	return writeTraceCSV(f, trace)
+}
+
+func writeTraceCSV(w io.Writer, trace []TraceRecord) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(traceHeader); err != nil {
+		return err
+	}
+
+	formatFloat := func(v float32) string {
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	}
+
+	for _, r := range trace {
+		if err := cw.Write([]string{
+			strconv.Itoa(r.Step),
+			strconv.FormatUint(uint64(r.ID), 10),
+			r.Kind,
+			formatFloat(r.X),
+			formatFloat(r.Y),
+			formatFloat(r.VX),
+			formatFloat(r.VY),
+			strconv.FormatBool(r.OnGround),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func readTrace(path string) ([]TraceRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if isJSON(path) {
+		var trace []TraceRecord
+		if err := json.NewDecoder(f).Decode(&trace); err != nil {
+			return nil, err
+		}
+		return trace, nil
+	}
+	return readTraceCSV(f)
+}
+
+func readTraceCSV(r io.Reader) ([]TraceRecord, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || !slices.Equal(rows[0], traceHeader) {
+		return nil, fmt.Errorf("trace is missing the header %s", strings.Join(traceHeader, ","))
+	}
+
+	trace := make([]TraceRecord, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		record, err := parseTraceRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("trace line %d: %w", i+2, err)
+		}
+		trace = append(trace, record)
+	}
+	return trace, nil
+}
+
+func parseTraceRow(row []string) (TraceRecord, error) {
+	var (
+		r      TraceRecord
+		err    error
+		values [4]float64
+	)
+
+	if r.Step, err = strconv.Atoi(row[0]); err != nil {
+		return r, err
+	}
+	id, err := strconv.ParseUint(row[1], 10, 32)
+	if err != nil {
+		return r, err
+	}
+	r.ID = uint32(id)
+	r.Kind = row[2]
+
+	for i := range values {
+		if values[i], err = strconv.ParseFloat(row[3+i], 32); err != nil {
+			return r, err
+		}
+	}
+	r.X, r.Y, r.VX, r.VY = float32(values[0]), float32(values[1]), float32(values[2]), float32(values[3])
+
+	if r.OnGround, err = strconv.ParseBool(row[7]); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// compareTraces returns a description of every record that differs from the golden trace by more than the tolerance.
+func compareTraces(trace, golden []TraceRecord, tolerance float64) []string {
+	var diffs []string
+
+	if len(trace) != len(golden) {
+		diffs = append(diffs, fmt.Sprintf("trace has %d records, golden has %d", len(trace), len(golden)))
+	}
+
+	differs := func(a, b float32) bool {
+		return math.Abs(float64(a-b)) > tolerance
+	}
+
+	for i := range min(len(trace), len(golden)) {
+		t, g := &trace[i], &golden[i]
+		switch {
+		case t.Step != g.Step || t.ID != g.ID || t.Kind != g.Kind:
+			diffs = append(diffs, fmt.Sprintf("record %d: got step %d %s %d, want step %d %s %d", i, t.Step, t.Kind, t.ID, g.Step, g.Kind, g.ID))
+		case differs(t.X, g.X) || differs(t.Y, g.Y) || differs(t.VX, g.VX) || differs(t.VY, g.VY) || t.OnGround != g.OnGround:
+			diffs = append(diffs, fmt.Sprintf("step %d %s %d: got pos (%g, %g) vel (%g, %g) ground %t, want pos (%g, %g) vel (%g, %g) ground %t",
+				t.Step, t.Kind, t.ID,
+				t.X, t.Y, t.VX, t.VY, t.OnGround,
+				g.X, g.Y, g.VX, g.VY, g.OnGround,
+			))
+		}
+	}
+
+	return diffs
+}
+
+// ========== Input Script ==========
+
+// inputKey is a run of steps during which a set of actions is held.
+type inputKey struct {
+	step    int
+	actions []input.InputAction
+}
+
+// inputScript replays held actions by step in place of the keyboard.
+//
+// Each line of a script holds a step followed by the names of the actions held from that step on,
+// until the next line. Blank lines and lines starting with # are ignored.
+//
+//	# step actions
+//	0   MoveRight
+//	60  MoveRight Jump
+//	70
+type inputScript struct {
+	keys []inputKey
+	step int
+}
+
+func readInputScript(path string) (*inputScript, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	script := &inputScript{}
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		step, err := strconv.Atoi(fields[0])
+		if err != nil || step < 0 {
+			return nil, fmt.Errorf("input script line %d: invalid step %q", line, fields[0])
+		}
+		if n := len(script.keys); n > 0 && step <= script.keys[n-1].step {
+			return nil, fmt.Errorf("input script line %d: step %d is not after step %d", line, step, script.keys[n-1].step)
+		}
+
+		key := inputKey{step: step}
+		for _, name := range fields[1:] {
+			action, ok := actions.ByName(name)
+			if !ok {
+				return nil, fmt.Errorf("input script line %d: unknown action %q", line, name)
+			}
+			key.actions = append(key.actions, action)
+		}
+		script.keys = append(script.keys, key)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return script, nil
+}
+
+// Source is the input.KeySource reporting the actions held at the script's current step.
+func (s *inputScript) Source(action input.InputAction, _ [2]ebiten.Key) bool {
+	index, found := slices.BinarySearchFunc(s.keys, s.step, func(k inputKey, step int) int {
+		return k.step - step
+	})
+	if !found {
+		index--
+	}
+	if index < 0 {
+		return false
+	}
+	return slices.Contains(s.keys[index].actions, action)
+}
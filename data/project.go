@@ -0,0 +1,7 @@
+package data
+
+import "github.com/adm87/deepdown/scripts/assets"
+
+// Project is the Tiled project declaring the custom property types shared by every map.
+// It lives outside the assets folder, so it is not part of the generated handles.
+const Project = assets.AssetHandle("data/deepdown.tiled-project")
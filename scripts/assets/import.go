@@ -32,6 +32,11 @@ func (ah AssetHandle) Root() string {
 	return s
 }
 
+// Resolve returns the handle of an asset referenced by a path relative to this asset, such as a Tiled file property.
+func (ah AssetHandle) Resolve(source string) AssetHandle {
+	return AssetHandle(resolveSourcePath(string(ah), source))
+}
+
 // AssetImporter is an interface for importing different types of assets.
 type AssetImporter interface {
 	AssetTypes() []string                                // AssetTypes returns the list of asset types the importer can handle.
@@ -48,6 +53,7 @@ func RegisterImporters(ctx deepdown.Context) {
 	addImporter(TmxImporter(ctx))
 	addImporter(TsxImporter(ctx))
 	addImporter(TxImporter(ctx))
+	addImporter(ProjectImporter(ctx))
 }
 
 // CanImport checks if there is an importer registered for the given file extension.
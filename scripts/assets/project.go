@@ -0,0 +1,131 @@
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/adm87/deepdown/scripts/deepdown"
+)
+
+// ========== Project ==========
+
+// Project is an imported Tiled project, holding the custom property types and project properties.
+type Project struct {
+	PropertyTypes []ProjectPropertyType `json:"propertyTypes"`
+	Properties    []ProjectProperty     `json:"properties"`
+}
+
+// ProjectPropertyType is a custom enum or class declared in a Tiled project.
+type ProjectPropertyType struct {
+	ID            int32             `json:"id"`
+	Name          string            `json:"name"`
+	Type          string            `json:"type"` // "enum" or "class"
+	StorageType   string            `json:"storageType"`
+	Values        []string          `json:"values"`
+	ValuesAsFlags bool              `json:"valuesAsFlags"`
+	Members       []ProjectProperty `json:"members"`
+}
+
+// ProjectProperty is a project property or a member of a class property type.
+// Tiled spells the custom type "propertytype" for project properties and "propertyType" for members,
+// both of which decode into PropertyType.
+type ProjectProperty struct {
+	Name         string          `json:"name"`
+	Type         string          `json:"type"`
+	PropertyType string          `json:"propertyType"`
+	Value        json.RawMessage `json:"value"`
+}
+
+// PropertyType returns the custom property type with the given name.
+func (p *Project) PropertyType(name string) (*ProjectPropertyType, bool) {
+	for i := range p.PropertyTypes {
+		if p.PropertyTypes[i].Name == name {
+			return &p.PropertyTypes[i], true
+		}
+	}
+	return nil, false
+}
+
+// Property returns the project property with the given name.
+func (p *Project) Property(name string) (*ProjectProperty, bool) {
+	for i := range p.Properties {
+		if p.Properties[i].Name == name {
+			return &p.Properties[i], true
+		}
+	}
+	return nil, false
+}
+
+// ClassValues returns the member values of a class property, using the class defaults for unset members.
+// Values are returned as raw JSON keyed by member name.
+func (p *Project) ClassValues(property *ProjectProperty) (map[string]json.RawMessage, error) {
+	class, ok := p.PropertyType(property.PropertyType)
+	if !ok || class.Type != "class" {
+		return nil, fmt.Errorf("property %s is not of a class type", property.Name)
+	}
+
+	values := make(map[string]json.RawMessage, len(class.Members))
+	for _, member := range class.Members {
+		values[member.Name] = member.Value
+	}
+
+	if len(property.Value) > 0 {
+		var overrides map[string]json.RawMessage
+		if err := json.Unmarshal(property.Value, &overrides); err != nil {
+			return nil, fmt.Errorf("property %s: %w", property.Name, err)
+		}
+		for name, value := range overrides {
+			values[name] = value
+		}
+	}
+
+	return values, nil
+}
+
+// EnumFlags returns the names set in a string flags enum value, stored by Tiled as comma separated names.
+func EnumFlags(value json.RawMessage) ([]string, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	var flags string
+	if err := json.Unmarshal(value, &flags); err != nil {
+		return nil, err
+	}
+	if flags == "" {
+		return nil, nil
+	}
+
+	names := strings.Split(flags, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return names, nil
+}
+
+// ========== Project Importer ==========
+
+type projectImporter struct {
+	ctx deepdown.Context
+}
+
+func (pi *projectImporter) AssetTypes() []string {
+	return []string{"tiled-project"}
+}
+
+func (pi *projectImporter) Import(handle AssetHandle, data []byte) (any, error) {
+	var project *Project
+
+	if err := json.Unmarshal(data, &project); err != nil {
+		pi.ctx.Logger().Error("Failed to unmarshal Tiled project", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func ProjectImporter(ctx deepdown.Context) AssetImporter {
+	return &projectImporter{ctx: ctx}
+}
@@ -37,7 +37,13 @@ func (ti *tmxImporter) Import(handle AssetHandle, data []byte) (any, error) {
 		tmx.Tilesets[i].Source = resolveSourcePath(string(handle), tmx.Tilesets[i].Source)
 	}
 
-	return tmx, nil
+	tilemap, err := decodeTilemap(handle, data, tmx)
+	if err != nil {
+		ti.ctx.Logger().Error("Failed to decode TMX tile layers", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return tilemap, nil
 }
 
 func TmxImporter(ctx deepdown.Context) AssetImporter {
@@ -64,7 +70,13 @@ func (tsi *tsxImporter) Import(handle AssetHandle, data []byte) (any, error) {
 
 	tsx.Image.Source = resolveSourcePath(string(handle), tsx.Image.Source)
 
-	return tsx, nil
+	tileset, err := decodeTileset(data, tsx)
+	if err != nil {
+		tsi.ctx.Logger().Error("Failed to decode TSX tiles", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return tileset, nil
 }
 
 func TsxImporter(ctx deepdown.Context) AssetImporter {
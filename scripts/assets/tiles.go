@@ -0,0 +1,609 @@
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/adm87/tiled"
+)
+
+// Tiled stores tile flip flags in the upper bits of a GID.
+const (
+	GIDFlipHorizontal uint32 = 0x80000000
+	GIDFlipVertical   uint32 = 0x40000000
+	GIDFlipDiagonal   uint32 = 0x20000000
+	GIDFlipMask              = GIDFlipHorizontal | GIDFlipVertical | GIDFlipDiagonal | 0x10000000
+)
+
+// ========== Tilemap ==========
+
+// Tilemap is an imported TMX map along with the tile layer data used by the engine.
+type Tilemap struct {
+	*tiled.Tmx
+
+	Handle     AssetHandle             // Handle the tilemap was loaded from
+	Infinite   bool                    // Set for maps whose tile layers are saved as chunks
+	Tilesets   []TilemapTileset        // Tilesets ordered by first GID
+	TileLayers []TileLayer             // Tile layers in draw order
+	Objects    map[int32]TilemapObject // Object attributes not decoded by tiled, by object ID
+}
+
+// TilemapTileset references a tileset used by a tilemap.
+type TilemapTileset struct {
+	FirstGID uint32
+	Source   AssetHandle
+}
+
+// TileLayer holds the GIDs of a tile layer, split into chunks of equal size.
+// Finite layers are a single chunk at the origin, while infinite layers keep the chunks saved by Tiled,
+// so memory only grows with the painted area of the map.
+type TileLayer struct {
+	Name          string
+	X, Y          int32 // Tile coordinates of the top-left corner of the layer's bounds, negative in infinite maps
+	Width, Height int32 // Size in tiles of the layer's bounds
+	Properties    map[string]string
+
+	ChunkWidth, ChunkHeight int32
+	Chunks                  map[[2]int32][]uint32 // GIDs in row-major order by chunk coordinates
+}
+
+// GID returns the GID at the given tile coordinates, including flip flags.
+func (tl *TileLayer) GID(x, y int32) uint32 {
+	if tl.ChunkWidth <= 0 || tl.ChunkHeight <= 0 {
+		return 0
+	}
+
+	cx, cy := floorDiv(x, tl.ChunkWidth), floorDiv(y, tl.ChunkHeight)
+	gids, ok := tl.Chunks[[2]int32{cx, cy}]
+	if !ok {
+		return 0
+	}
+	return gids[(y-cy*tl.ChunkHeight)*tl.ChunkWidth+x-cx*tl.ChunkWidth]
+}
+
+// addChunk copies the GIDs of a chunk at the given tile coordinates into the layer's chunks,
+// which may not be aligned with the chunks saved by Tiled.
+func (tl *TileLayer) addChunk(x, y, width, height int32, gids []uint32) {
+	for row := range height {
+		for col := range width {
+			gid := gids[row*width+col]
+			if gid == 0 {
+				continue
+			}
+
+			tx, ty := x+col, y+row
+			key := [2]int32{floorDiv(tx, tl.ChunkWidth), floorDiv(ty, tl.ChunkHeight)}
+			chunk, ok := tl.Chunks[key]
+			if !ok {
+				chunk = make([]uint32, tl.ChunkWidth*tl.ChunkHeight)
+				tl.Chunks[key] = chunk
+			}
+			chunk[(ty-key[1]*tl.ChunkHeight)*tl.ChunkWidth+tx-key[0]*tl.ChunkWidth] = gid
+		}
+	}
+}
+
+func floorDiv(a, b int32) int32 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// ObjectShape identifies the shape of a Tiled object.
+type ObjectShape uint8
+
+const (
+	ObjectShapeRectangle ObjectShape = iota
+	ObjectShapeEllipse
+	ObjectShapePoint
+	ObjectShapePolygon
+	ObjectShapePolyline
+	ObjectShapeTile // A tile object, positioned by its bottom-left corner
+)
+
+func (s ObjectShape) String() string {
+	switch s {
+	case ObjectShapeRectangle:
+		return "Rectangle"
+	case ObjectShapeEllipse:
+		return "Ellipse"
+	case ObjectShapePoint:
+		return "Point"
+	case ObjectShapePolygon:
+		return "Polygon"
+	case ObjectShapePolyline:
+		return "Polyline"
+	case ObjectShapeTile:
+		return "Tile"
+	default:
+		return "Unknown"
+	}
+}
+
+func (s ObjectShape) IsValid() bool {
+	return s <= ObjectShapeTile
+}
+
+// TilemapObject holds the attributes of an object in an object group that the tiled package does not decode.
+type TilemapObject struct {
+	ID       int32
+	Name     string
+	Class    string // Class of the object, or its type in maps saved before Tiled 1.9
+	Shape    ObjectShape
+	Rotation float32 // Clockwise rotation in degrees around the object's position
+}
+
+// Object returns the attributes of the object with the given ID.
+// Unknown objects are reported as unrotated rectangles.
+func (tm *Tilemap) Object(id int32) TilemapObject {
+	if obj, ok := tm.Objects[id]; ok {
+		return obj
+	}
+	return TilemapObject{ID: id}
+}
+
+// TileBounds returns the tile coordinates covered by the tile layers, max exclusive.
+// Finite maps cover their declared size and infinite maps the chunks painted in any layer.
+func (tm *Tilemap) TileBounds() (minX, minY, maxX, maxY int32) {
+	if !tm.Infinite {
+		return 0, 0, tm.Width, tm.Height
+	}
+
+	for i, layer := range tm.TileLayers {
+		if i == 0 {
+			minX, minY, maxX, maxY = layer.X, layer.Y, layer.X+layer.Width, layer.Y+layer.Height
+			continue
+		}
+		minX, minY = min(minX, layer.X), min(minY, layer.Y)
+		maxX, maxY = max(maxX, layer.X+layer.Width), max(maxY, layer.Y+layer.Height)
+	}
+	return minX, minY, maxX, maxY
+}
+
+// LoadTilemap loads a tilemap along with the tilesets and images it uses.
+func LoadTilemap(handle AssetHandle) (*Tilemap, error) {
+	if err := Load(handle); err != nil {
+		return nil, err
+	}
+
+	tm, ok := Get[*Tilemap](handle)
+	if !ok {
+		return nil, fmt.Errorf("asset is not a tilemap: %s", handle)
+	}
+
+	for _, ts := range tm.Tilesets {
+		if err := Load(ts.Source); err != nil {
+			return nil, err
+		}
+		tileset, ok := Get[*Tileset](ts.Source)
+		if !ok {
+			return nil, fmt.Errorf("asset is not a tileset: %s", ts.Source)
+		}
+		if err := Load(AssetHandle(tileset.Image.Source)); err != nil {
+			return nil, err
+		}
+	}
+
+	return tm, nil
+}
+
+// TilesetByGID returns the tileset containing the given GID.
+func (tm *Tilemap) TilesetByGID(gid uint32) (TilemapTileset, bool) {
+	gid &^= GIDFlipMask
+	for i := len(tm.Tilesets) - 1; i >= 0; i-- {
+		if gid >= tm.Tilesets[i].FirstGID {
+			return tm.Tilesets[i], true
+		}
+	}
+	return TilemapTileset{}, false
+}
+
+// ========== Tileset ==========
+
+// Tileset is an imported TSX tileset along with the per-tile data used by the engine.
+type Tileset struct {
+	*tiled.Tsx
+
+	Tiles map[uint32]*Tile // Tiles with custom data by local tile ID
+}
+
+// Tile holds the custom data of a single tile in a tileset.
+type Tile struct {
+	ID         uint32
+	Properties map[string]string
+	Collision  []TileShape // Shapes from the tile's collision object group
+	Animation  []TileFrame // Frames of the tile's animation, empty for static tiles
+
+	animationLength uint32 // Total duration of the animation in milliseconds
+}
+
+// TileFrame is a single frame of a tile animation.
+type TileFrame struct {
+	TileID   uint32 // Local ID of the tile shown during the frame
+	Duration uint32 // Milliseconds
+}
+
+// Animated returns true if the tile has an animation with a duration.
+func (t *Tile) Animated() bool {
+	return t.animationLength > 0
+}
+
+// AnimationLength returns the duration of one loop of the tile's animation in seconds.
+func (t *Tile) AnimationLength() float64 {
+	return float64(t.animationLength) / 1000
+}
+
+// Frame returns the local ID of the tile shown after the given seconds of the looping animation,
+// or the tile's own ID if it is not animated.
+func (t *Tile) Frame(seconds float64) uint32 {
+	if t.animationLength == 0 {
+		return t.ID
+	}
+
+	elapsed := uint32(math.Mod(math.Max(seconds, 0)*1000, float64(t.animationLength)))
+	for _, frame := range t.Animation {
+		if elapsed < frame.Duration {
+			return frame.TileID
+		}
+		elapsed -= frame.Duration
+	}
+	return t.Animation[len(t.Animation)-1].TileID
+}
+
+// TileShape is a collision shape relative to the top-left corner of its tile.
+type TileShape struct {
+	X, Y          float32
+	Width, Height float32
+	Points        [][2]float32 // Polygon points relative to X, Y; empty for rectangles
+	Properties    map[string]string
+}
+
+// ========== XML ==========
+
+type xmlProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type xmlTmx struct {
+	Infinite bool `xml:"infinite,attr"`
+	Tilesets []struct {
+		FirstGID uint32 `xml:"firstgid,attr"`
+		Source   string `xml:"source,attr"`
+	} `xml:"tileset"`
+	Layers []struct {
+		Name       string        `xml:"name,attr"`
+		Width      int32         `xml:"width,attr"`
+		Height     int32         `xml:"height,attr"`
+		Properties []xmlProperty `xml:"properties>property"`
+		Data       xmlData       `xml:"data"`
+	} `xml:"layer"`
+	ObjectGroups []struct {
+		Objects []xmlObject `xml:"object"`
+	} `xml:"objectgroup"`
+}
+
+type xmlObject struct {
+	ID       int32     `xml:"id,attr"`
+	Name     string    `xml:"name,attr"`
+	Class    string    `xml:"class,attr"`
+	Type     string    `xml:"type,attr"`
+	Rotation float32   `xml:"rotation,attr"`
+	GID      uint32    `xml:"gid,attr"`
+	Ellipse  *struct{} `xml:"ellipse"`
+	Point    *struct{} `xml:"point"`
+	Polygon  *struct{} `xml:"polygon"`
+	Polyline *struct{} `xml:"polyline"`
+}
+
+func (obj *xmlObject) class() string {
+	if obj.Class != "" {
+		return obj.Class
+	}
+	return obj.Type
+}
+
+func (obj *xmlObject) shape() ObjectShape {
+	switch {
+	case obj.Ellipse != nil:
+		return ObjectShapeEllipse
+	case obj.Point != nil:
+		return ObjectShapePoint
+	case obj.Polygon != nil:
+		return ObjectShapePolygon
+	case obj.Polyline != nil:
+		return ObjectShapePolyline
+	case obj.GID != 0:
+		return ObjectShapeTile
+	default:
+		return ObjectShapeRectangle
+	}
+}
+
+type xmlData struct {
+	Encoding    string     `xml:"encoding,attr"`
+	Compression string     `xml:"compression,attr"`
+	Content     string     `xml:",chardata"`
+	Chunks      []xmlChunk `xml:"chunk"`
+}
+
+type xmlChunk struct {
+	X       int32  `xml:"x,attr"`
+	Y       int32  `xml:"y,attr"`
+	Width   int32  `xml:"width,attr"`
+	Height  int32  `xml:"height,attr"`
+	Content string `xml:",chardata"`
+}
+
+type xmlTsx struct {
+	Tiles []struct {
+		ID          uint32        `xml:"id,attr"`
+		Properties  []xmlProperty `xml:"properties>property"`
+		ObjectGroup struct {
+			Objects []struct {
+				X          float32       `xml:"x,attr"`
+				Y          float32       `xml:"y,attr"`
+				Width      float32       `xml:"width,attr"`
+				Height     float32       `xml:"height,attr"`
+				Properties []xmlProperty `xml:"properties>property"`
+				Polygon    *struct {
+					Points string `xml:"points,attr"`
+				} `xml:"polygon"`
+			} `xml:"object"`
+		} `xml:"objectgroup"`
+		Animation []struct {
+			TileID   uint32 `xml:"tileid,attr"`
+			Duration uint32 `xml:"duration,attr"`
+		} `xml:"animation>frame"`
+	} `xml:"tile"`
+}
+
+func decodeTilemap(handle AssetHandle, data []byte, tmx *tiled.Tmx) (*Tilemap, error) {
+	var raw xmlTmx
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	tm := &Tilemap{
+		Tmx:        tmx,
+		Handle:     handle,
+		Infinite:   raw.Infinite,
+		Tilesets:   make([]TilemapTileset, 0, len(raw.Tilesets)),
+		TileLayers: make([]TileLayer, 0, len(raw.Layers)),
+		Objects:    make(map[int32]TilemapObject),
+	}
+
+	for _, ts := range raw.Tilesets {
+		tm.Tilesets = append(tm.Tilesets, TilemapTileset{
+			FirstGID: ts.FirstGID,
+			Source:   AssetHandle(resolveSourcePath(string(handle), ts.Source)),
+		})
+	}
+
+	for _, layer := range raw.Layers {
+		tl, err := decodeTileLayer(layer.Data, raw.Infinite, layer.Width, layer.Height)
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", layer.Name, err)
+		}
+		tl.Name = layer.Name
+		tl.Properties = decodeProperties(layer.Properties)
+		tm.TileLayers = append(tm.TileLayers, tl)
+	}
+
+	for _, group := range raw.ObjectGroups {
+		for _, obj := range group.Objects {
+			tm.Objects[obj.ID] = TilemapObject{
+				ID:       obj.ID,
+				Name:     obj.Name,
+				Class:    obj.class(),
+				Shape:    obj.shape(),
+				Rotation: obj.Rotation,
+			}
+		}
+	}
+
+	return tm, nil
+}
+
+// decodeTileLayer decodes the GIDs of a finite layer into a single chunk, or the chunks of an infinite layer.
+func decodeTileLayer(data xmlData, infinite bool, width, height int32) (TileLayer, error) {
+	if !infinite {
+		gids, err := decodeLayerData(data, int(width*height))
+		if err != nil {
+			return TileLayer{}, err
+		}
+		return TileLayer{
+			Width:       width,
+			Height:      height,
+			ChunkWidth:  max(width, 1),
+			ChunkHeight: max(height, 1),
+			Chunks:      map[[2]int32][]uint32{{0, 0}: gids},
+		}, nil
+	}
+
+	tl := TileLayer{Chunks: make(map[[2]int32][]uint32, len(data.Chunks))}
+	if len(data.Chunks) == 0 {
+		tl.ChunkWidth, tl.ChunkHeight = 1, 1
+		return tl, nil
+	}
+
+	// Tiled saves every chunk of a map with the same size
+	tl.ChunkWidth, tl.ChunkHeight = data.Chunks[0].Width, data.Chunks[0].Height
+	if tl.ChunkWidth <= 0 || tl.ChunkHeight <= 0 {
+		return tl, fmt.Errorf("invalid chunk size: %dx%d", tl.ChunkWidth, tl.ChunkHeight)
+	}
+
+	maxX, maxY := int32(0), int32(0)
+	for i, chunk := range data.Chunks {
+		gids, err := decodeLayerData(xmlData{
+			Encoding:    data.Encoding,
+			Compression: data.Compression,
+			Content:     chunk.Content,
+		}, int(chunk.Width*chunk.Height))
+		if err != nil {
+			return tl, fmt.Errorf("chunk %d,%d: %w", chunk.X, chunk.Y, err)
+		}
+		tl.addChunk(chunk.X, chunk.Y, chunk.Width, chunk.Height, gids)
+
+		if i == 0 {
+			tl.X, tl.Y = chunk.X, chunk.Y
+			maxX, maxY = chunk.X+chunk.Width, chunk.Y+chunk.Height
+			continue
+		}
+		tl.X, tl.Y = min(tl.X, chunk.X), min(tl.Y, chunk.Y)
+		maxX, maxY = max(maxX, chunk.X+chunk.Width), max(maxY, chunk.Y+chunk.Height)
+	}
+	tl.Width, tl.Height = maxX-tl.X, maxY-tl.Y
+
+	return tl, nil
+}
+
+func decodeTileset(data []byte, tsx *tiled.Tsx) (*Tileset, error) {
+	var raw xmlTsx
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	ts := &Tileset{
+		Tsx:   tsx,
+		Tiles: make(map[uint32]*Tile, len(raw.Tiles)),
+	}
+
+	for _, t := range raw.Tiles {
+		tile := &Tile{
+			ID:         t.ID,
+			Properties: decodeProperties(t.Properties),
+		}
+
+		for _, obj := range t.ObjectGroup.Objects {
+			shape := TileShape{
+				X:          obj.X,
+				Y:          obj.Y,
+				Width:      obj.Width,
+				Height:     obj.Height,
+				Properties: decodeProperties(obj.Properties),
+			}
+			if obj.Polygon != nil {
+				points, err := decodePoints(obj.Polygon.Points)
+				if err != nil {
+					return nil, fmt.Errorf("tile %d: %w", t.ID, err)
+				}
+				shape.Points = points
+			}
+			tile.Collision = append(tile.Collision, shape)
+		}
+
+		for _, frame := range t.Animation {
+			tile.Animation = append(tile.Animation, TileFrame{TileID: frame.TileID, Duration: frame.Duration})
+			tile.animationLength += frame.Duration
+		}
+
+		ts.Tiles[t.ID] = tile
+	}
+
+	return ts, nil
+}
+
+func decodeProperties(properties []xmlProperty) map[string]string {
+	if len(properties) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(properties))
+	for _, p := range properties {
+		result[p.Name] = p.Value
+	}
+	return result
+}
+
+func decodePoints(value string) ([][2]float32, error) {
+	fields := strings.Fields(value)
+	points := make([][2]float32, 0, len(fields))
+	for _, field := range fields {
+		x, y, ok := strings.Cut(field, ",")
+		if !ok {
+			return nil, fmt.Errorf("invalid point: %s", field)
+		}
+		px, err := strconv.ParseFloat(x, 32)
+		if err != nil {
+			return nil, err
+		}
+		py, err := strconv.ParseFloat(y, 32)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, [2]float32{float32(px), float32(py)})
+	}
+	return points, nil
+}
+
+// decodeLayerData decodes the GIDs of a layer or chunk, which must hold exactly count tiles.
+func decodeLayerData(data xmlData, count int) ([]uint32, error) {
+	var gids []uint32
+
+	switch data.Encoding {
+	case "csv":
+		gids = make([]uint32, 0, count)
+		for _, field := range strings.Split(data.Content, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids = append(gids, uint32(gid))
+		}
+
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data.Content))
+		if err != nil {
+			return nil, err
+		}
+
+		var reader io.Reader = bytes.NewReader(raw)
+		switch data.Compression {
+		case "":
+		case "zlib":
+			if reader, err = zlib.NewReader(reader); err != nil {
+				return nil, err
+			}
+		case "gzip":
+			if reader, err = gzip.NewReader(reader); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported layer compression: %s", data.Compression)
+		}
+
+		if raw, err = io.ReadAll(reader); err != nil {
+			return nil, err
+		}
+		if len(raw)%4 != 0 {
+			return nil, fmt.Errorf("invalid layer data length: %d bytes", len(raw))
+		}
+		gids = make([]uint32, len(raw)/4)
+		for i := range gids {
+			gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported layer encoding: %s", data.Encoding)
+	}
+
+	if len(gids) != count {
+		return nil, fmt.Errorf("expected %d tiles, got %d", count, len(gids))
+	}
+	return gids, nil
+}
@@ -8,6 +8,7 @@ import (
 var (
 	DrawCollisionCells      = false
 	DrawPotentialCollisions = false
+	DrawPhysicsStats        = false
 	DrawTilemap             = true
 )
 
@@ -20,6 +21,10 @@ func PollInput() error {
 		ebiten.SetFullscreen(!ebiten.IsFullscreen())
 	}
 
+	if inpututil.IsKeyJustPressed(ebiten.KeyF7) {
+		DrawPhysicsStats = !DrawPhysicsStats
+	}
+
 	if inpututil.IsKeyJustPressed(ebiten.KeyF8) {
 		DrawTilemap = !DrawTilemap
 	}
@@ -0,0 +1,62 @@
+package ecs
+
+// Each calls fn for every entity with an A component, walking the storage in packed order.
+//
+// Components of other types may be added while iterating, and entities destroyed during the iteration
+// are only removed once it ends. Components must not be removed from the iterated storages directly.
+func Each[A any](w *World, fn func(e Entity, a *A)) {
+	as := Components[A](w)
+
+	w.lock()
+	defer w.unlock()
+
+	for i := 0; i < len(as.entities); i++ {
+		fn(as.entities[i], &as.dense[i])
+	}
+}
+
+// Each2 calls fn for every entity with both an A and a B component.
+// It walks the smaller of the two storages and looks the other component up, see Each.
+func Each2[A, B any](w *World, fn func(e Entity, a *A, b *B)) {
+	as, bs := Components[A](w), Components[B](w)
+
+	w.lock()
+	defer w.unlock()
+
+	if as.Len() <= bs.Len() {
+		for i := 0; i < len(as.entities); i++ {
+			e := as.entities[i]
+			if b := bs.Get(e); b != nil {
+				fn(e, &as.dense[i], b)
+			}
+		}
+		return
+	}
+
+	for i := 0; i < len(bs.entities); i++ {
+		e := bs.entities[i]
+		if a := as.Get(e); a != nil {
+			fn(e, a, &bs.dense[i])
+		}
+	}
+}
+
+// Each3 calls fn for every entity with an A, a B and a C component.
+// It walks the A storage, so A should be the rarest component, see Each.
+func Each3[A, B, C any](w *World, fn func(e Entity, a *A, b *B, c *C)) {
+	as, bs, cs := Components[A](w), Components[B](w), Components[C](w)
+
+	w.lock()
+	defer w.unlock()
+
+	for i := 0; i < len(as.entities); i++ {
+		e := as.entities[i]
+		b := bs.Get(e)
+		if b == nil {
+			continue
+		}
+		if c := cs.Get(e); c != nil {
+			fn(e, &as.dense[i], b, c)
+		}
+	}
+}
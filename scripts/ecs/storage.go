@@ -0,0 +1,120 @@
+package ecs
+
+// Storage holds the components of one type in a sparse set.
+//
+// Components are packed in a dense slice in no particular order, and a sparse slice indexed by entity
+// maps each entity to its component. Lookups are constant time and iterating walks contiguous memory.
+// Removing a component moves the last component into its slot, so pointers returned by Add and Get
+// are only valid until the storage next changes.
+type Storage[T any] struct {
+	sparse   []int32 // Dense index plus one by entity index, 0 when the entity has no component
+	dense    []T
+	entities []Entity // Owner of each dense component
+
+	onRemove func(e Entity, value *T)
+}
+
+// Len returns the number of components in the storage.
+func (s *Storage[T]) Len() int {
+	return len(s.dense)
+}
+
+// Values returns the packed components, in the same order as Entities.
+// The slice is owned by the storage and only valid until the storage next changes.
+func (s *Storage[T]) Values() []T {
+	return s.dense
+}
+
+// Entities returns the owners of the packed components, in the same order as Values.
+// The slice is owned by the storage and only valid until the storage next changes.
+func (s *Storage[T]) Entities() []Entity {
+	return s.entities
+}
+
+// OnRemove sets a function called before a component is removed, including when its entity is destroyed.
+// It is used to release resources held by components, such as colliders added to a physics world.
+func (s *Storage[T]) OnRemove(fn func(e Entity, value *T)) {
+	s.onRemove = fn
+}
+
+// Add sets the component of an entity, replacing any component it already had, and returns it.
+// Replacing a component does not call the OnRemove function.
+func (s *Storage[T]) Add(e Entity, value T) *T {
+	if i, ok := s.index(e); ok {
+		s.dense[i] = value
+		return &s.dense[i]
+	}
+
+	index := int(e.Index())
+	if index >= len(s.sparse) {
+		s.sparse = append(s.sparse, make([]int32, index+1-len(s.sparse))...)
+	}
+
+	s.dense = append(s.dense, value)
+	s.entities = append(s.entities, e)
+	s.sparse[index] = int32(len(s.dense))
+	return &s.dense[len(s.dense)-1]
+}
+
+// Get returns the component of an entity, or nil if it has none.
+func (s *Storage[T]) Get(e Entity) *T {
+	if i, ok := s.index(e); ok {
+		return &s.dense[i]
+	}
+	return nil
+}
+
+// Has returns true if the entity has a component in the storage.
+func (s *Storage[T]) Has(e Entity) bool {
+	_, ok := s.index(e)
+	return ok
+}
+
+// Remove removes the component of an entity, returning false if it had none.
+// Components must not be removed from a storage while it is being iterated, see World.Destroy.
+func (s *Storage[T]) Remove(e Entity) bool {
+	return s.remove(e)
+}
+
+func (s *Storage[T]) remove(e Entity) bool {
+	i, ok := s.index(e)
+	if !ok {
+		return false
+	}
+
+	if s.onRemove != nil {
+		s.onRemove(e, &s.dense[i])
+	}
+
+	// The hook may have changed the storage
+	if i, ok = s.index(e); !ok {
+		return true
+	}
+
+	last := len(s.dense) - 1
+	if i != last {
+		s.dense[i] = s.dense[last]
+		s.entities[i] = s.entities[last]
+		s.sparse[s.entities[i].Index()] = int32(i + 1)
+	}
+
+	var zero T
+	s.dense[last] = zero
+	s.dense = s.dense[:last]
+	s.entities = s.entities[:last]
+	s.sparse[e.Index()] = 0
+	return true
+}
+
+// index returns the dense index of an entity's component, checking the generation to ignore stale entities.
+func (s *Storage[T]) index(e Entity) (int, bool) {
+	index := int(e.Index())
+	if index >= len(s.sparse) || s.sparse[index] == 0 {
+		return 0, false
+	}
+	i := int(s.sparse[index] - 1)
+	if s.entities[i] != e {
+		return 0, false
+	}
+	return i, true
+}
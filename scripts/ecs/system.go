@@ -0,0 +1,49 @@
+package ecs
+
+// Phase selects when a system runs, mirroring the Update, FixedUpdate and LateUpdate steps of the game loop.
+type Phase uint8
+
+const (
+	PhaseUpdate      Phase = iota // Once per tick, before fixed updates
+	PhaseFixedUpdate              // Once per fixed step
+	PhaseLateUpdate               // Once per tick, after fixed updates
+	PhaseCount
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseUpdate:
+		return "Update"
+	case PhaseFixedUpdate:
+		return "FixedUpdate"
+	case PhaseLateUpdate:
+		return "LateUpdate"
+	default:
+		return "Unknown"
+	}
+}
+
+func (p Phase) IsValid() bool {
+	return p < PhaseCount
+}
+
+// System updates the entities of a world for one phase.
+type System func(w *World, dt float64)
+
+// AddSystem appends a system to a phase. Systems of a phase run in the order they were added.
+func (w *World) AddSystem(phase Phase, system System) {
+	if !phase.IsValid() {
+		panic("invalid system phase")
+	}
+	w.systems[phase] = append(w.systems[phase], system)
+}
+
+// Run runs the systems of a phase in order. Entities destroyed by the systems are removed once the phase ends.
+func (w *World) Run(phase Phase, dt float64) {
+	w.lock()
+	defer w.unlock()
+
+	for _, system := range w.systems[phase] {
+		system(w, dt)
+	}
+}
@@ -0,0 +1,177 @@
+package ecs
+
+import (
+	"reflect"
+)
+
+// Entity identifies an entity by its index and generation.
+// The generation changes each time an index is reused, so ids of destroyed entities are never alive again.
+type Entity uint64
+
+// NoEntity is the zero entity, which is never alive.
+const NoEntity Entity = 0
+
+func newEntity(index, generation uint32) Entity {
+	return Entity(generation)<<32 | Entity(index)
+}
+
+// Index returns the slot of the entity, shared with destroyed entities that held it before.
+func (e Entity) Index() uint32 {
+	return uint32(e)
+}
+
+// Generation returns the number of times the entity's slot had been used when it was created.
+func (e Entity) Generation() uint32 {
+	return uint32(e >> 32)
+}
+
+// storage is implemented by every component storage so the world can remove components of destroyed entities.
+type storage interface {
+	remove(e Entity) bool
+}
+
+// World owns entities, their component storages and the systems updating them.
+type World struct {
+	generations []uint32 // Current generation by entity index, starting at 1
+	live        []bool   // Whether the entity holding each index is alive
+	free        []uint32 // Indices of destroyed entities available for reuse
+	alive       int
+
+	storages map[reflect.Type]storage
+	order    []storage // Storages in creation order, used to remove components deterministically
+
+	systems [PhaseCount][]System
+
+	locked  int      // Number of iterations and phases in progress
+	pending []Entity // Entities destroyed while locked
+}
+
+// NewWorld returns an empty world.
+func NewWorld() *World {
+	return &World{
+		storages: make(map[reflect.Type]storage),
+	}
+}
+
+// Create returns a new entity without components.
+func (w *World) Create() Entity {
+	if n := len(w.free); n > 0 {
+		index := w.free[n-1]
+		w.free = w.free[:n-1]
+		w.live[index] = true
+		w.alive++
+		return newEntity(index, w.generations[index])
+	}
+
+	index := uint32(len(w.generations))
+	w.generations = append(w.generations, 1)
+	w.live = append(w.live, true)
+	w.alive++
+	return newEntity(index, 1)
+}
+
+// Alive returns true if the entity was created by the world and has not been destroyed.
+func (w *World) Alive(e Entity) bool {
+	index := e.Index()
+	return e != NoEntity && int(index) < len(w.generations) && w.live[index] && w.generations[index] == e.Generation()
+}
+
+// Len returns the number of alive entities.
+func (w *World) Len() int {
+	return w.alive
+}
+
+// Destroy removes every component of an entity and frees its id.
+// While systems run or components are iterated, destruction is deferred until the outermost one returns,
+// so storages are never reordered under an iteration.
+func (w *World) Destroy(e Entity) {
+	if !w.Alive(e) {
+		return
+	}
+	if w.locked > 0 {
+		w.pending = append(w.pending, e)
+		return
+	}
+	w.destroy(e)
+}
+
+func (w *World) destroy(e Entity) {
+	if !w.Alive(e) {
+		return
+	}
+
+	for _, s := range w.order {
+		s.remove(e)
+	}
+
+	index := e.Index()
+	w.generations[index]++
+	if w.generations[index] == 0 {
+		w.generations[index] = 1 // Skip the generation of NoEntity on wrap around
+	}
+	w.live[index] = false
+	w.free = append(w.free, index)
+	w.alive--
+}
+
+// Clear destroys every entity, keeping the storages and systems.
+func (w *World) Clear() {
+	for index, generation := range w.generations {
+		w.Destroy(newEntity(uint32(index), generation))
+	}
+}
+
+func (w *World) lock() {
+	w.locked++
+}
+
+func (w *World) unlock() {
+	w.locked--
+	if w.locked > 0 {
+		return
+	}
+
+	for len(w.pending) > 0 {
+		pending := w.pending
+		w.pending = nil
+		for _, e := range pending {
+			w.destroy(e)
+		}
+	}
+}
+
+// Components returns the storage of components of type T, creating it on first use.
+func Components[T any](w *World) *Storage[T] {
+	t := reflect.TypeFor[T]()
+	if s, ok := w.storages[t]; ok {
+		return s.(*Storage[T])
+	}
+
+	s := &Storage[T]{}
+	w.storages[t] = s
+	w.order = append(w.order, s)
+	return s
+}
+
+// Add sets the T component of an alive entity and returns it.
+func Add[T any](w *World, e Entity, value T) *T {
+	if !w.Alive(e) {
+		return nil
+	}
+	return Components[T](w).Add(e, value)
+}
+
+// Get returns the T component of an entity, or nil if it has none.
+func Get[T any](w *World, e Entity) *T {
+	return Components[T](w).Get(e)
+}
+
+// Has returns true if the entity has a T component.
+func Has[T any](w *World, e Entity) bool {
+	return Components[T](w).Has(e)
+}
+
+// Remove removes the T component of an entity, returning false if it had none.
+func Remove[T any](w *World, e Entity) bool {
+	return Components[T](w).Remove(e)
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/adm87/deepdown/scripts/input"
 	"github.com/adm87/deepdown/scripts/input/actions"
 	"github.com/adm87/deepdown/scripts/level"
-	"github.com/adm87/tiled"
+	"github.com/adm87/deepdown/scripts/physics"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -27,7 +27,10 @@ const (
 type Game struct {
 	ctx deepdown.Context
 
-	lvl *level.Level
+	levels *level.Manager
+
+	collectStats bool
+	paused       bool
 
 	dt              float64
 	fixDt           float64
@@ -36,6 +39,7 @@ type Game struct {
 
 func NewGame(ctx deepdown.Context) *Game {
 	assets.MustLoad(
+		data.Project,
 		data.GymCollision,
 		data.SampleSheet,
 		data.TilemapPacked,
@@ -47,31 +51,21 @@ func NewGame(ctx deepdown.Context) *Game {
 	width := float32(TargetWidth) * float32(Scale)
 	height := float32(TargetHeight) * float32(Scale)
 
-	lvl := level.NewLevel(ctx, width, height)
-	lvl.SetTmx(assets.MustGet[*tiled.Tmx](data.GymCollision))
-
-	input.Register(
-		input.NewKeyHoldBinding(
-			actions.MoveLeft,
-			actions.MovementHoldThresh,
-			[2]ebiten.Key{ebiten.KeyA, ebiten.KeyLeft},
-		),
-		input.NewKeyHoldBinding(
-			actions.MoveRight,
-			actions.MovementHoldThresh,
-			[2]ebiten.Key{ebiten.KeyD, ebiten.KeyRight},
-		),
-		input.NewKeyPressDurationBinding(
-			actions.Jump,
-			actions.JumpThresh,
-			[2]ebiten.Key{ebiten.KeySpace, ebiten.KeyUp},
-		),
-	)
+	if err := level.LoadCollisionLayers(assets.MustGet[*assets.Project](data.Project)); err != nil {
+		panic(err)
+	}
+
+	levels := level.NewManager(ctx, width, height)
+	if err := levels.Load(data.GymCollision); err != nil {
+		panic(err)
+	}
+
+	actions.RegisterBindings()
 
 	return &Game{
-		ctx:   ctx,
-		lvl:   lvl,
-		fixDt: 1.0 / 60.0,
+		ctx:    ctx,
+		levels: levels,
+		fixDt:  1.0 / 60.0,
 	}
 }
 
@@ -81,26 +75,64 @@ func (g *Game) Update() error {
 	}
 
 	g.dt = 1.0 / float64(ebiten.TPS())
-	g.accumulatedTime += g.dt
+	if !g.paused {
+		g.accumulatedTime += g.dt
+	}
 
 	if g.accumulatedTime > MaxFixedSteps*g.fixDt {
 		g.accumulatedTime = MaxFixedSteps * g.fixDt
 	}
 
+	g.levels.Level().World().SetCollectStats(g.collectStats || debug.DrawPhysicsStats)
+
 	input.Update(g.dt)
 
-	g.lvl.Update(g.dt)
+	if pause := input.GetBinding[*input.KeyBinding](actions.Pause); pause != nil && pause.JustPressed() {
+		g.SetPaused(!g.paused)
+	}
+	if g.paused {
+		return nil
+	}
+
+	g.levels.Update(g.dt)
 	for g.accumulatedTime >= g.fixDt {
-		g.lvl.FixedUpdate(g.fixDt)
+		g.levels.FixedUpdate(g.fixDt)
 		g.accumulatedTime -= g.fixDt
 	}
-	g.lvl.LateUpdate(g.dt)
+	g.levels.LateUpdate(g.dt)
 
 	return nil
 }
 
+// SetPaused freezes or resumes the level, including its physics, entities and tile animations.
+// Input keeps being polled while paused so the pause action can resume the game.
+func (g *Game) SetPaused(paused bool) {
+	g.paused = paused
+}
+
+// Paused returns true while the game is paused.
+func (g *Game) Paused() bool {
+	return g.paused
+}
+
+// SetCollectStats keeps physics step statistics collected even while the debug overlay is hidden.
+func (g *Game) SetCollectStats(enabled bool) {
+	g.collectStats = enabled
+}
+
+// PhysicsStats returns the statistics of the last physics step. It is safe to call from other goroutines.
+func (g *Game) PhysicsStats() physics.StepStats {
+	return g.levels.Level().World().Stats()
+}
+
+// Alpha returns the fraction of a fixed step accumulated since the last fixed update,
+// used to interpolate rendering between the last two steps.
+func (g *Game) Alpha() float32 {
+	return float32(g.accumulatedTime / g.fixDt)
+}
+
 func (g *Game) Draw(screen *ebiten.Image) {
-	g.lvl.Draw(screen)
+	g.levels.Draw(screen, g.Alpha())
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
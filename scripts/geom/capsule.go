@@ -0,0 +1,68 @@
+package geom
+
+import "math"
+
+// Capsule represents a line segment, relative to its position, inflated by a radius.
+type Capsule struct {
+	X, Y       float32
+	Start, End [2]float32
+	Radius     float32
+}
+
+func NewCapsule(x, y float32, start, end [2]float32, radius float32) Capsule {
+	return Capsule{X: x, Y: y, Start: start, End: end, Radius: radius}
+}
+
+// Segment returns the capsule's segment in world space.
+func (c *Capsule) Segment() (start, end [2]float32) {
+	start = [2]float32{c.X + c.Start[0], c.Y + c.Start[1]}
+	end = [2]float32{c.X + c.End[0], c.Y + c.End[1]}
+	return
+}
+
+func (c *Capsule) ContainsPoint(px, py float32) bool {
+	start, end := c.Segment()
+	closest := ClosestPointOnSegment([2]float32{px, py}, start, end)
+	dx, dy := px-closest[0], py-closest[1]
+	return dx*dx+dy*dy <= c.Radius*c.Radius
+}
+
+// SurfaceAt returns the top-most Y of the capsule at the given X.
+func (c *Capsule) SurfaceAt(x float32) (surfaceY float32, found bool) {
+	start, end := c.Segment()
+
+	// Rounded ends
+	for _, p := range [2][2]float32{start, end} {
+		dx := x - p[0]
+		if Abs(dx) > c.Radius {
+			continue
+		}
+		y := p[1] - float32(math.Sqrt(float64(c.Radius*c.Radius-dx*dx)))
+		if !found || y < surfaceY {
+			surfaceY, found = y, true
+		}
+	}
+
+	// Segment offset by the radius along its normal
+	if dx := end[0] - start[0]; Abs(dx) > Epsilon && x >= min(start[0], end[0]) && x <= max(start[0], end[0]) {
+		slope := (end[1] - start[1]) / dx
+		y := start[1] + (x-start[0])*slope - c.Radius*float32(math.Sqrt(float64(1+slope*slope)))
+		if !found || y < surfaceY {
+			surfaceY, found = y, true
+		}
+	}
+
+	return surfaceY, found
+}
+
+// ========== AABB interface ==========
+
+func (c *Capsule) Min() (x, y float32) {
+	return c.X + min(c.Start[0], c.End[0]) - c.Radius, c.Y + min(c.Start[1], c.End[1]) - c.Radius
+}
+
+func (c *Capsule) Max() (x, y float32) {
+	return c.X + max(c.Start[0], c.End[0]) + c.Radius, c.Y + max(c.Start[1], c.End[1]) + c.Radius
+}
+
+// ========== AABB interface ==========
@@ -0,0 +1,52 @@
+package geom
+
+import "math"
+
+// Circle represents a circle centered on its position.
+type Circle struct {
+	X, Y   float32
+	Radius float32
+}
+
+func NewCircle(x, y, radius float32) Circle {
+	return Circle{X: x, Y: y, Radius: radius}
+}
+
+func (c *Circle) Center() (x, y float32) {
+	return c.X, c.Y
+}
+
+func (c *Circle) ContainsPoint(px, py float32) bool {
+	dx, dy := px-c.X, py-c.Y
+	return dx*dx+dy*dy <= c.Radius*c.Radius
+}
+
+// SurfaceAt returns the top-most Y of the circle at the given X.
+func (c *Circle) SurfaceAt(x float32) (surfaceY float32, found bool) {
+	dx := x - c.X
+	if Abs(dx) > c.Radius {
+		return 0, false
+	}
+	return c.Y - float32(math.Sqrt(float64(c.Radius*c.Radius-dx*dx))), true
+}
+
+// SurfaceNormalAt returns the normal of the top of the circle at the given X.
+func (c *Circle) SurfaceNormalAt(x float32) (normal [2]float32, found bool) {
+	y, found := c.SurfaceAt(x)
+	if !found || c.Radius <= 0 {
+		return [2]float32{0, -1}, found
+	}
+	return [2]float32{(x - c.X) / c.Radius, (y - c.Y) / c.Radius}, true
+}
+
+// ========== AABB interface ==========
+
+func (c *Circle) Min() (x, y float32) {
+	return c.X - c.Radius, c.Y - c.Radius
+}
+
+func (c *Circle) Max() (x, y float32) {
+	return c.X + c.Radius, c.Y + c.Radius
+}
+
+// ========== AABB interface ==========
@@ -0,0 +1,155 @@
+package geom
+
+// DecomposePolygon splits a simple polygon into convex pieces.
+//
+// The polygon is triangulated by ear clipping and adjacent pieces are then merged while the result stays convex,
+// which keeps the number of pieces low for typical level geometry. Convex polygons are returned unchanged.
+// The pieces share the winding of EnsureCCWPolygon. It returns nil if the polygon is degenerate or self-intersecting.
+func DecomposePolygon(points [][2]float32) [][][2]float32 {
+	if len(points) < 3 || Abs(PolygonArea(points)) <= Epsilon {
+		return nil
+	}
+
+	points = append([][2]float32(nil), points...)
+	EnsureCCWPolygon(points)
+
+	if IsConvexPolygon(points) {
+		return [][][2]float32{points}
+	}
+
+	triangles := triangulate(points)
+	if triangles == nil {
+		return nil
+	}
+
+	pieces := mergeConvexPieces(points, triangles)
+
+	result := make([][][2]float32, len(pieces))
+	for i, piece := range pieces {
+		result[i] = make([][2]float32, len(piece))
+		for j, idx := range piece {
+			result[i][j] = points[idx]
+		}
+	}
+	return result
+}
+
+// triangulate clips ears from a polygon with positive area, returning triangles as vertex indices.
+func triangulate(points [][2]float32) [][]int {
+	remaining := make([]int, len(points))
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	triangles := make([][]int, 0, len(points)-2)
+	for len(remaining) > 3 {
+		n := len(remaining)
+		clipped := false
+
+		for i := range n {
+			a, b, c := remaining[(i+n-1)%n], remaining[i], remaining[(i+1)%n]
+			if cross(points[a], points[b], points[c]) <= Epsilon || !isEar(points, remaining, a, b, c) {
+				continue
+			}
+			triangles = append(triangles, []int{a, b, c})
+			remaining = append(remaining[:i], remaining[i+1:]...)
+			clipped = true
+			break
+		}
+
+		if clipped {
+			continue
+		}
+
+		// Collinear vertices are never ears, drop one and retry
+		dropped := false
+		for i := range n {
+			a, b, c := remaining[(i+n-1)%n], remaining[i], remaining[(i+1)%n]
+			if Abs(cross(points[a], points[b], points[c])) <= Epsilon {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				dropped = true
+				break
+			}
+		}
+		if !dropped {
+			return nil
+		}
+	}
+
+	if cross(points[remaining[0]], points[remaining[1]], points[remaining[2]]) > Epsilon {
+		triangles = append(triangles, remaining)
+	}
+	return triangles
+}
+
+// isEar returns true if no other remaining vertex lies within the triangle a, b, c.
+func isEar(points [][2]float32, remaining []int, a, b, c int) bool {
+	for _, idx := range remaining {
+		if idx == a || idx == b || idx == c {
+			continue
+		}
+		p := points[idx]
+		if p == points[a] || p == points[b] || p == points[c] {
+			continue
+		}
+		if cross(points[a], points[b], p) >= 0 && cross(points[b], points[c], p) >= 0 && cross(points[c], points[a], p) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeConvexPieces repeatedly joins pieces sharing an edge while the joined piece stays convex.
+func mergeConvexPieces(points [][2]float32, pieces [][]int) [][]int {
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(pieces) && !merged; i++ {
+			for j := i + 1; j < len(pieces) && !merged; j++ {
+				joined, ok := joinPieces(pieces[i], pieces[j])
+				if !ok || !IsConvexPolygon(indexedPoints(points, joined)) {
+					continue
+				}
+				pieces[i] = joined
+				pieces = append(pieces[:j], pieces[j+1:]...)
+				merged = true
+			}
+		}
+	}
+	return pieces
+}
+
+// joinPieces joins two pieces along an edge that runs u to v in a and v to u in b.
+func joinPieces(a, b []int) ([]int, bool) {
+	for i := range a {
+		u, v := a[i], a[(i+1)%len(a)]
+		for j := range b {
+			if b[j] != v || b[(j+1)%len(b)] != u {
+				continue
+			}
+
+			// Walk a from v around to u, then b from after u around to before v
+			joined := make([]int, 0, len(a)+len(b)-2)
+			for k := range len(a) {
+				joined = append(joined, a[(i+1+k)%len(a)])
+			}
+			for k := 2; k < len(b); k++ {
+				joined = append(joined, b[(j+k)%len(b)])
+			}
+			return joined, true
+		}
+	}
+	return nil, false
+}
+
+func indexedPoints(points [][2]float32, indices []int) [][2]float32 {
+	result := make([][2]float32, len(indices))
+	for i, idx := range indices {
+		result[i] = points[idx]
+	}
+	return result
+}
+
+// cross returns the z component of (b - a) x (c - b), positive when a, b, c turn with positive polygon area.
+func cross(a, b, c [2]float32) float32 {
+	return (b[0]-a[0])*(c[1]-b[1]) - (b[1]-a[1])*(c[0]-b[0])
+}
@@ -0,0 +1,190 @@
+package geom
+
+// Polygon represents a convex polygon defined by points relative to its position.
+type Polygon struct {
+	X, Y       float32
+	minX, minY float32
+	maxX, maxY float32
+	points     [][2]float32
+}
+
+func NewPolygon(x, y float32, points [][2]float32) Polygon {
+	p := Polygon{
+		X: x,
+		Y: y,
+	}
+	p.SetPoints(points)
+	return p
+}
+
+// SetPoints copies the given points into the polygon.
+// It panics if the points do not form a convex polygon.
+func (p *Polygon) SetPoints(points [][2]float32) {
+	if len(points) < 3 {
+		panic("polygon must have at least three points")
+	}
+
+	p.points = append(p.points[:0], points...)
+	EnsureCCWPolygon(p.points)
+
+	if !IsConvexPolygon(p.points) {
+		panic("polygon must be convex")
+	}
+
+	p.minX, p.minY, p.maxX, p.maxY = ComputePolygonAABB(p.points)
+}
+
+// Clear removes all points from the polygon, keeping the allocated storage.
+func (p *Polygon) Clear() {
+	p.points = p.points[:0]
+	p.minX, p.minY, p.maxX, p.maxY = 0, 0, 0, 0
+}
+
+func (p *Polygon) Points() [][2]float32 {
+	return p.points
+}
+
+func (p *Polygon) VertexCount() int {
+	return len(p.points)
+}
+
+func (p *Polygon) GetVertex(i int) (x, y float32) {
+	return p.X + p.points[i][0], p.Y + p.points[i][1]
+}
+
+func (p *Polygon) ContainsPoint(px, py float32) bool {
+	n := len(p.points)
+	for i := range n {
+		x1, y1 := p.GetVertex(i)
+		x2, y2 := p.GetVertex((i + 1) % n)
+
+		// Points inside a CCW polygon are never on the outer side of an edge
+		if (x2-x1)*(py-y1)-(y2-y1)*(px-x1) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SurfaceAt returns the top-most Y of the polygon at the given X.
+func (p *Polygon) SurfaceAt(x float32) (surfaceY float32, found bool) {
+	n := len(p.points)
+	for i := range n {
+		x1, y1 := p.GetVertex(i)
+		x2, y2 := p.GetVertex((i + 1) % n)
+
+		if x < min(x1, x2) || x > max(x1, x2) {
+			continue
+		}
+
+		y := y1
+		if Abs(x2-x1) > Epsilon {
+			y = y1 + (x-x1)/(x2-x1)*(y2-y1)
+		} else {
+			y = min(y1, y2)
+		}
+
+		if !found || y < surfaceY {
+			surfaceY, found = y, true
+		}
+	}
+	return
+}
+
+// SurfaceNormalAt returns the upward facing normal of the top-most edge of the polygon at the given X.
+// Where edges meet, the flattest of them is used.
+func (p *Polygon) SurfaceNormalAt(x float32) (normal [2]float32, found bool) {
+	var surfaceY float32
+	n := len(p.points)
+	for i := range n {
+		x1, y1 := p.GetVertex(i)
+		x2, y2 := p.GetVertex((i + 1) % n)
+
+		if x < min(x1, x2) || x > max(x1, x2) {
+			continue
+		}
+
+		y := min(y1, y2)
+		if Abs(x2-x1) > Epsilon {
+			y = y1 + (x-x1)/(x2-x1)*(y2-y1)
+		}
+
+		edgeNormal := ComputeSlopeNormal([2]float32{x1, y1}, [2]float32{x2, y2})
+		if edgeNormal[1] > 0 {
+			edgeNormal = [2]float32{-edgeNormal[0], -edgeNormal[1]}
+		}
+
+		switch {
+		case !found || y < surfaceY-Epsilon:
+			surfaceY, normal, found = y, edgeNormal, true
+		case y <= surfaceY+Epsilon && edgeNormal[1] < normal[1]:
+			normal = edgeNormal
+		}
+	}
+	return
+}
+
+// ========== AABB interface ==========
+
+func (p *Polygon) Min() (x, y float32) {
+	return p.X + p.minX, p.Y + p.minY
+}
+
+func (p *Polygon) Max() (x, y float32) {
+	return p.X + p.maxX, p.Y + p.maxY
+}
+
+// ========== AABB interface ==========
+
+func PolygonArea(points [][2]float32) float32 {
+	var area float32
+	for i := range points {
+		j := (i + 1) % len(points)
+		area += points[i][0]*points[j][1] - points[j][0]*points[i][1]
+	}
+	return area * 0.5
+}
+
+// EnsureCCWPolygon reverses the points in place if they are not in the same winding as EnsureCCWTriangle.
+func EnsureCCWPolygon(points [][2]float32) {
+	switch area := PolygonArea(points); {
+	case area > 0:
+		return
+	case area < 0:
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	default:
+		panic("polygon area cannot be zero")
+	}
+}
+
+func IsConvexPolygon(points [][2]float32) bool {
+	n := len(points)
+	var sign float32
+	for i := range n {
+		p0, p1, p2 := points[i], points[(i+1)%n], points[(i+2)%n]
+		cross := (p1[0]-p0[0])*(p2[1]-p1[1]) - (p1[1]-p0[1])*(p2[0]-p1[0])
+		if Abs(cross) <= Epsilon {
+			continue
+		}
+		if sign == 0 {
+			sign = cross
+		} else if (sign > 0) != (cross > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func ComputePolygonAABB(points [][2]float32) (minX, minY, maxX, maxY float32) {
+	minX, minY = points[0][0], points[0][1]
+	maxX, maxY = points[0][0], points[0][1]
+
+	for _, p := range points[1:] {
+		minX, maxX = min(minX, p[0]), max(maxX, p[0])
+		minY, maxY = min(minY, p[1]), max(maxY, p[1])
+	}
+
+	return
+}
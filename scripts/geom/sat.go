@@ -0,0 +1,108 @@
+package geom
+
+import "math"
+
+// SAT tests two convex hulls for overlap using the separating axis theorem.
+//
+// Each hull is a list of world space vertices inflated by a radius, which covers
+// polygons (three or more vertices), capsules (two vertices) and circles (one vertex).
+// The returned normal points from b towards a, so moving a by normal*depth separates the hulls.
+func SAT(a [][2]float32, radiusA float32, b [][2]float32, radiusB float32) (normal [2]float32, depth float32, overlaps bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return
+	}
+
+	depth = math.MaxFloat32
+
+	test := func(axisX, axisY float32) bool {
+		length := float32(math.Sqrt(float64(axisX*axisX + axisY*axisY)))
+		if length <= Epsilon {
+			return true
+		}
+		axisX, axisY = axisX/length, axisY/length
+
+		minA, maxA := projectHull(a, axisX, axisY)
+		minB, maxB := projectHull(b, axisX, axisY)
+		minA, maxA = minA-radiusA, maxA+radiusA
+		minB, maxB = minB-radiusB, maxB+radiusB
+
+		overlap := min(maxA-minB, maxB-minA)
+		if overlap <= 0 {
+			return false
+		}
+		if overlap < depth {
+			depth = overlap
+			normal = [2]float32{axisX, axisY}
+		}
+		return true
+	}
+
+	if !testEdgeAxes(a, test) || !testEdgeAxes(b, test) {
+		return [2]float32{}, 0, false
+	}
+
+	// Rounded hulls can also be separated along the axes between vertices
+	if radiusA > 0 || radiusB > 0 {
+		for i := range a {
+			for j := range b {
+				if !test(a[i][0]-b[j][0], a[i][1]-b[j][1]) {
+					return [2]float32{}, 0, false
+				}
+			}
+		}
+	}
+
+	// Hulls sharing a single center have no axis between them
+	if depth == math.MaxFloat32 {
+		return [2]float32{0, -1}, radiusA + radiusB, radiusA+radiusB > 0
+	}
+
+	// Orient the normal from b towards a
+	centerAX, centerAY := hullCenter(a)
+	centerBX, centerBY := hullCenter(b)
+	if (centerAX-centerBX)*normal[0]+(centerAY-centerBY)*normal[1] < 0 {
+		normal[0], normal[1] = -normal[0], -normal[1]
+	}
+
+	return normal, depth, true
+}
+
+func testEdgeAxes(hull [][2]float32, test func(axisX, axisY float32) bool) bool {
+	n := len(hull)
+	if n < 2 {
+		return true
+	}
+
+	// A segment has a single axis, a polygon has one per edge
+	edges := n
+	if n == 2 {
+		edges = 1
+	}
+
+	for i := range edges {
+		p1, p2 := hull[i], hull[(i+1)%n]
+		if !test(-(p2[1] - p1[1]), p2[0]-p1[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+func projectHull(hull [][2]float32, axisX, axisY float32) (minP, maxP float32) {
+	minP = hull[0][0]*axisX + hull[0][1]*axisY
+	maxP = minP
+	for i := 1; i < len(hull); i++ {
+		p := hull[i][0]*axisX + hull[i][1]*axisY
+		minP, maxP = min(minP, p), max(maxP, p)
+	}
+	return
+}
+
+func hullCenter(hull [][2]float32) (x, y float32) {
+	for i := range hull {
+		x += hull[i][0]
+		y += hull[i][1]
+	}
+	n := float32(len(hull))
+	return x / n, y / n
+}
@@ -4,7 +4,10 @@ import (
 	"math"
 )
 
-func abs(value float32) float32 {
+const Epsilon float32 = 0.0001
+
+// Abs returns the absolute value of a float32.
+func Abs(value float32) float32 {
 	if value < 0 {
 		return -value
 	}
@@ -75,7 +78,7 @@ func FindTriangleSurfaceAt(x float32, triangle *Triangle) (surfaceY float32, fou
 		x2, y2 := triangle.X+points[((i+1)%3)*2], triangle.Y+points[((i+1)%3)*2+1]
 
 		// Skip vertical or horizontal edges
-		if abs(x2-x1) < 0.1 || abs(y2-y1) < 0.1 {
+		if Abs(x2-x1) < 0.1 || Abs(y2-y1) < 0.1 {
 			continue
 		}
 
@@ -128,3 +131,16 @@ func LineIntersects(aLine [2][2]float32, bLine [2][2]float32) ([2]float32, bool)
 
 	return [2]float32{}, false
 }
+
+func ClosestPointOnSegment(p, start, end [2]float32) [2]float32 {
+	edgeX, edgeY := end[0]-start[0], end[1]-start[1]
+	lengthSq := edgeX*edgeX + edgeY*edgeY
+	if lengthSq <= Epsilon {
+		return start
+	}
+
+	t := ((p[0]-start[0])*edgeX + (p[1]-start[1])*edgeY) / lengthSq
+	t = max(0, min(1, t))
+
+	return [2]float32{start[0] + edgeX*t, start[1] + edgeY*t}
+}
@@ -2,6 +2,7 @@ package actions
 
 import (
 	"github.com/adm87/deepdown/scripts/input"
+	"github.com/hajimehoshi/ebiten/v2"
 )
 
 const (
@@ -10,6 +11,7 @@ const (
 	MoveUp
 	MoveDown
 	Jump
+	Pause
 )
 
 const (
@@ -18,5 +20,67 @@ const (
 	MovementDampening  = 0.8
 	JumpVelocity       = -85.0
 	JumpThresh         = 0.1
-	Gravity            = 400.0
+	ClimbSpeed         = 40.0
 )
+
+var actionNames = map[input.InputAction]string{
+	MoveLeft:  "MoveLeft",
+	MoveRight: "MoveRight",
+	MoveUp:    "MoveUp",
+	MoveDown:  "MoveDown",
+	Jump:      "Jump",
+	Pause:     "Pause",
+}
+
+// Name returns the name of a player action.
+func Name(action input.InputAction) string {
+	if name, ok := actionNames[action]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// ByName returns the player action matching the given name.
+func ByName(name string) (input.InputAction, bool) {
+	for action, actionName := range actionNames {
+		if actionName == name {
+			return action, true
+		}
+	}
+	return 0, false
+}
+
+// RegisterBindings registers the keyboard bindings of the player actions.
+func RegisterBindings() {
+	input.Register(
+		input.NewKeyHoldBinding(
+			MoveLeft,
+			MovementHoldThresh,
+			[2]ebiten.Key{ebiten.KeyA, ebiten.KeyLeft},
+		),
+		input.NewKeyHoldBinding(
+			MoveRight,
+			MovementHoldThresh,
+			[2]ebiten.Key{ebiten.KeyD, ebiten.KeyRight},
+		),
+		input.NewKeyHoldBinding(
+			MoveUp,
+			MovementHoldThresh,
+			[2]ebiten.Key{ebiten.KeyW, ebiten.KeyUp},
+		),
+		input.NewKeyHoldBinding(
+			MoveDown,
+			MovementHoldThresh,
+			[2]ebiten.Key{ebiten.KeyS, ebiten.KeyDown},
+		),
+		input.NewKeyPressDurationBinding(
+			Jump,
+			JumpThresh,
+			[2]ebiten.Key{ebiten.KeySpace, ebiten.KeyUp},
+		),
+		input.NewKeyBinding(
+			Pause,
+			[2]ebiten.Key{ebiten.KeyP, ebiten.KeyPause},
+		),
+	)
+}
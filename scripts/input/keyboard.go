@@ -2,6 +2,29 @@ package input
 
 import "github.com/hajimehoshi/ebiten/v2"
 
+// KeySource reports whether any of the keys bound to an action are pressed.
+type KeySource func(action InputAction, keys [2]ebiten.Key) bool
+
+var keySource KeySource = keyboardSource
+
+// SetKeySource replaces the source key bindings read from, such as a scripted input sequence.
+// Passing nil restores the keyboard.
+func SetKeySource(source KeySource) {
+	if source == nil {
+		source = keyboardSource
+	}
+	keySource = source
+}
+
+func keyboardSource(action InputAction, keys [2]ebiten.Key) bool {
+	for i := range keys {
+		if ebiten.IsKeyPressed(keys[i]) {
+			return true
+		}
+	}
+	return false
+}
+
 // =========== KeyBinding ==========
 
 type KeyBinding struct {
@@ -10,15 +33,16 @@ type KeyBinding struct {
 	prevActive bool
 }
 
+func NewKeyBinding(action InputAction, keys [2]ebiten.Key) *KeyBinding {
+	return &KeyBinding{
+		Binding: Binding{action: action},
+		keys:    keys,
+	}
+}
+
 func (b *KeyBinding) Update(dt float64) {
 	b.prevActive = b.active
-	for i := range b.keys {
-		if ebiten.IsKeyPressed(b.keys[i]) {
-			b.active = true
-			return
-		}
-	}
-	b.active = false
+	b.active = keySource(b.action, b.keys)
 }
 
 func (b *KeyBinding) IsActive() bool {
@@ -0,0 +1,73 @@
+package level
+
+import (
+	"strconv"
+
+	"github.com/adm87/deepdown/scripts/assets"
+	"github.com/adm87/deepdown/scripts/ecs"
+)
+
+// TileAnimationOffset is the tileset tile property that desyncs an animated tile. By default every instance of
+// an animated tile shows the same frame, following the level clock. When the property is set, each instance
+// starts at a point of the animation derived from its map cell, or from its entity for sprites, so rows of
+// torches or water do not flicker in lockstep.
+const TileAnimationOffset = "AnimationOffset"
+
+// animationState holds the clock driving tile animations.
+type animationState struct {
+	clock  float64 // Seconds of animation played since the level was set
+	paused bool
+}
+
+// AnimationClock returns the seconds of tile animation played since the tilemap was set.
+func (l *Level) AnimationClock() float64 {
+	return l.animation.clock
+}
+
+// SetAnimationsPaused stops or resumes tile animations. The clock also stands still whenever the level
+// is not updated, so animations freeze with the rest of the level while the game is paused.
+func (l *Level) SetAnimationsPaused(paused bool) {
+	l.animation.paused = paused
+}
+
+// AnimationsPaused returns true if tile animations are paused.
+func (l *Level) AnimationsPaused() bool {
+	return l.animation.paused
+}
+
+// animateTiles advances the clock of tile animations.
+func (l *Level) animateTiles(w *ecs.World, dt float64) {
+	if !l.animation.paused {
+		l.animation.clock += dt
+	}
+}
+
+// tileFrame returns the local ID of the tile drawn for a tile at the current animation clock.
+// Seed varies the start of the animation between instances of tiles using TileAnimationOffset.
+func (l *Level) tileFrame(tsx *assets.Tileset, tileID, seed uint32) uint32 {
+	tile, ok := tsx.Tiles[tileID]
+	if !ok || !tile.Animated() {
+		return tileID
+	}
+
+	t := l.animation.clock
+	if offset, _ := strconv.ParseBool(tile.Properties[TileAnimationOffset]); offset {
+		t += float64(hashSeed(seed)%1024) / 1024 * tile.AnimationLength()
+	}
+	return tile.Frame(t)
+}
+
+// cellSeed returns the animation seed of the map cell at x, y.
+func cellSeed(x, y int32) uint32 {
+	return uint32(x)*73856093 ^ uint32(y)*19349663
+}
+
+// hashSeed scrambles a seed so neighboring seeds land on unrelated offsets.
+func hashSeed(seed uint32) uint32 {
+	seed ^= seed >> 16
+	seed *= 0x7feb352d
+	seed ^= seed >> 15
+	seed *= 0x846ca68b
+	seed ^= seed >> 16
+	return seed
+}
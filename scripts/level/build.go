@@ -1,14 +1,18 @@
 package level
 
 import (
+	"fmt"
+	"log/slog"
 	"strconv"
 
+	"github.com/adm87/deepdown/scripts/assets"
 	"github.com/adm87/deepdown/scripts/physics"
 	"github.com/adm87/tiled"
-	"github.com/adm87/tiled/tilemap"
 )
 
-func (l *Level) BuildStaticCollision(collisionGroup *tiled.ObjectGroup) error {
+// BuildStaticCollision builds static colliders from the objects of a collision object group.
+// Each object may build several colliders, see objectColliders.
+func (l *Level) BuildStaticCollision(tm *assets.Tilemap, collisionGroup *tiled.ObjectGroup) error {
 	if collisionGroup == nil || len(collisionGroup.Objects) == 0 {
 		l.ctx.Logger().Warn("No collision objects found")
 		return nil
@@ -17,25 +21,74 @@ func (l *Level) BuildStaticCollision(collisionGroup *tiled.ObjectGroup) error {
 	for i := range collisionGroup.Objects {
 		obj := &collisionGroup.Objects[i]
 
-		var collider physics.Collider
+		role, err := collisionRole(obj.Properties)
+		if err != nil {
+			return err
+		}
+
+		material, err := physicsMaterial(obj.Properties)
+		if err != nil {
+			return err
+		}
+
+		layer, err := collisionLayer(obj.Properties)
+		if err != nil {
+			return err
+		}
+
+		for _, collider := range l.objectColliders(tm, obj) {
+			collider.Info().Role = role
+			collider.Info().Layer = layer
+			collider.Info().State = physics.ColliderStateStatic
+			collider.Info().Material = material
+
+			l.world.AddCollider(collider)
+		}
+	}
+
+	return nil
+}
+
+func (l *Level) BuildKinematics(platformGroup *tiled.ObjectGroup) error {
+	if platformGroup == nil || len(platformGroup.Objects) == 0 {
+		return nil
+	}
+
+	for i := range platformGroup.Objects {
+		obj := &platformGroup.Objects[i]
+
+		// Polylines are paths referenced by platforms
+		if len(obj.Polyline.Points) > 0 {
+			continue
+		}
 
-		if len(obj.Polygon.Points) > 0 {
-			collider = physics.GetTriangleCollider(obj.X, obj.Y, [6]float32(obj.Polygon.Points))
-		} else {
-			collider = physics.GetBoxCollider(obj.X, obj.Y, obj.Width, obj.Height)
+		collider := l.world.Pool().GetBoxCollider(obj.X, obj.Y, obj.Width, obj.Height)
+
+		role, err := collisionRole(obj.Properties)
+		if err != nil {
+			return err
+		}
+
+		material, err := physicsMaterial(obj.Properties)
+		if err != nil {
+			return err
+		}
+
+		layer, err := collisionLayer(obj.Properties)
+		if err != nil {
+			return err
 		}
 
-		var role physics.Role
-		if prop := tiled.PropertyByType(obj.Properties, "CollisionRole"); prop != nil {
-			bit, err := strconv.Atoi(prop.Value)
-			if err != nil {
-				return err
-			}
-			role = physics.Role(bit >> 1)
+		path, err := l.buildPath(platformGroup, obj)
+		if err != nil {
+			return err
 		}
 
 		collider.Info().Role = role
-		collider.Info().State = physics.ColliderStateStatic
+		collider.Info().Layer = layer
+		collider.Info().State = physics.ColliderStateKinematic
+		collider.Info().Material = material
+		collider.Info().Path = path
 
 		l.world.AddCollider(collider)
 	}
@@ -43,36 +96,167 @@ func (l *Level) BuildStaticCollision(collisionGroup *tiled.ObjectGroup) error {
 	return nil
 }
 
-func (l *Level) BuildPlayer(spawnGroup *tiled.ObjectGroup, tmx *tiled.Tmx) error {
-	if spawnGroup == nil || len(spawnGroup.Objects) == 0 {
-		l.ctx.Logger().Warn("No player spawn object found")
-		return nil
+func (l *Level) buildPath(group *tiled.ObjectGroup, obj *tiled.Object) (*physics.Path, error) {
+	prop := propertyByName(obj.Properties, "Path")
+	if prop == nil {
+		return nil, nil
+	}
+
+	id, err := strconv.Atoi(prop.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathObj *tiled.Object
+	for i := range group.Objects {
+		if int(group.Objects[i].ID) == id {
+			pathObj = &group.Objects[i]
+			break
+		}
 	}
 
-	for i := range spawnGroup.Objects {
-		obj := &spawnGroup.Objects[i]
+	if pathObj == nil || len(pathObj.Polyline.Points) < 2 {
+		l.ctx.Logger().Warn("Platform path not found", slog.Int("path", id))
+		return nil, nil
+	}
 
-		l.player = &Player{}
-		l.player.X = obj.X
-		l.player.Y = obj.Y
-		l.player.Width = obj.Width * 0.5
-		l.player.Height = obj.Height * 0.7
+	points := make([][2]float32, 0, len(pathObj.Polyline.Points)/2)
+	for i := 0; i+1 < len(pathObj.Polyline.Points); i += 2 {
+		points = append(points, [2]float32{
+			pathObj.X + pathObj.Polyline.Points[i],
+			pathObj.Y + pathObj.Polyline.Points[i+1],
+		})
+	}
 
-		l.player.BoxCollider = *physics.GetBoxCollider(obj.X, obj.Y, l.player.Width, l.player.Height)
-		l.player.BoxCollider.Info().State = physics.ColliderStateDynamic
-		l.player.Offset[0] = (obj.Width - l.player.Width) * 0.5
-		l.player.Offset[1] = (obj.Height - l.player.Height)
+	speed := DefaultPathSpeed
+	if err := floatProperty(obj.Properties, "Speed", &speed); err != nil {
+		return nil, err
+	}
 
-		data, ok := tilemap.GetTileData(obj.GID, tmx, obj.X, obj.Y)
+	mode := physics.PathModeLoop
+	if prop := propertyByName(obj.Properties, "PathMode"); prop != nil {
+		m, ok := physics.PathModeByName(prop.Value)
 		if !ok {
-			l.ctx.Logger().Warn("No tile data found for player spawn")
+			return nil, fmt.Errorf("unknown path mode: %s", prop.Value)
 		}
-		l.player.Data = data
+		mode = m
+	}
 
-		l.world.AddCollider(&l.player.BoxCollider)
+	return physics.NewPath(points, speed, mode), nil
+}
 
-		l.ctx.Logger().Info("Player spawn created at ", obj.X, ", ", obj.Y)
+func collisionRole(properties []tiled.Property) (physics.Role, error) {
+	if prop := tiled.PropertyByType(properties, "CollisionRole"); prop != nil {
+		return parseCollisionRole(prop.Value)
 	}
+	return physics.CollisionRoleNone, nil
+}
 
+// parseCollisionRole converts a CollisionRole enum value from Tiled into a physics role.
+// Tiled stores flag enums with the first value ("None") as bit 0.
+func parseCollisionRole(value string) (physics.Role, error) {
+	bit, err := strconv.Atoi(value)
+	if err != nil {
+		return physics.CollisionRoleNone, err
+	}
+	return physics.Role(bit >> 1), nil
+}
+
+func propertyByName(properties []tiled.Property, name string) *tiled.Property {
+	for i := range properties {
+		if properties[i].Name == name {
+			return &properties[i]
+		}
+	}
+	return nil
+}
+
+// physicsConfig returns the default physics config with any overrides from the map properties.
+func physicsConfig(properties []tiled.Property) (physics.Config, error) {
+	config := physics.DefaultConfig()
+	overrides := map[string]*float32{
+		"Gravity":              &config.Gravity,
+		"MaxVelocityRiseSpeed": &config.MaxVelocityRiseSpeed,
+		"MaxVelocityFallSpeed": &config.MaxVelocityFallSpeed,
+		"VelocityDamping":      &config.VelocityDamping,
+		"GroundCheckDistance":  &config.GroundCheckDistance,
+		"GroundCheckTolerance": &config.GroundCheckTolerance,
+		"GridCellSize":         &config.GridCellSize,
+		"TreeMargin":           &config.TreeMargin,
+		"SimulationMargin":     &config.SimulationMargin,
+		"MaxSlopeAngle":        &config.MaxSlopeAngle,
+		"WaterGravityScale":    &config.WaterGravityScale,
+		"WaterBuoyancy":        &config.WaterBuoyancy,
+		"WaterDrag":            &config.WaterDrag,
+	}
+	for name, value := range overrides {
+		if err := floatProperty(properties, name, value); err != nil {
+			return config, err
+		}
+	}
+	if err := intProperty(properties, "SleepSteps", &config.SleepSteps); err != nil {
+		return config, err
+	}
+	if err := intProperty(properties, "ConstraintIterations", &config.ConstraintIterations); err != nil {
+		return config, err
+	}
+	if err := boolProperty(properties, "DebugPools", &config.DebugPools); err != nil {
+		return config, err
+	}
+	if prop := propertyByName(properties, "Broadphase"); prop != nil {
+		broadphase, ok := physics.BroadphaseTypeByName(prop.Value)
+		if !ok {
+			return config, fmt.Errorf("invalid broadphase: %s", prop.Value)
+		}
+		config.Broadphase = broadphase
+	}
+	if config.GridCellSize <= 0 {
+		return config, fmt.Errorf("invalid grid cell size: %f", config.GridCellSize)
+	}
+	return config, nil
+}
+
+// physicsMaterial returns the default material with any overrides from the object properties.
+func physicsMaterial(properties []tiled.Property) (physics.Material, error) {
+	material := physics.DefaultMaterial()
+	if err := floatProperty(properties, "Friction", &material.Friction); err != nil {
+		return material, err
+	}
+	if err := floatProperty(properties, "Restitution", &material.Restitution); err != nil {
+		return material, err
+	}
+	return material, nil
+}
+
+func floatProperty(properties []tiled.Property, name string, value *float32) error {
+	if prop := propertyByName(properties, name); prop != nil {
+		v, err := strconv.ParseFloat(prop.Value, 32)
+		if err != nil {
+			return err
+		}
+		*value = float32(v)
+	}
+	return nil
+}
+
+func intProperty(properties []tiled.Property, name string, value *int32) error {
+	if prop := propertyByName(properties, name); prop != nil {
+		v, err := strconv.ParseInt(prop.Value, 10, 32)
+		if err != nil {
+			return err
+		}
+		*value = int32(v)
+	}
+	return nil
+}
+
+func boolProperty(properties []tiled.Property, name string, value *bool) error {
+	if prop := propertyByName(properties, name); prop != nil {
+		v, err := strconv.ParseBool(prop.Value)
+		if err != nil {
+			return err
+		}
+		*value = v
+	}
 	return nil
 }
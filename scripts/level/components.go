@@ -0,0 +1,61 @@
+package level
+
+import (
+	"github.com/adm87/deepdown/scripts/ecs"
+	"github.com/adm87/deepdown/scripts/physics"
+	"github.com/adm87/tiled/tilemap"
+)
+
+// Transform is the position of an entity in the level.
+// Entities with a collider have their transform synced to the collider after every fixed step.
+type Transform struct {
+	X, Y float32
+}
+
+// Collider attaches a physics collider to an entity. The collider is removed from the level's
+// physics world and released to its pool when the component is removed or the entity destroyed.
+type Collider struct {
+	physics.Collider
+}
+
+// Sprite draws a tile at an entity's position, interpolated between fixed steps when the entity has a collider.
+type Sprite struct {
+	Data tilemap.Data
+}
+
+// Controller moves an entity's collider as a character instead of as a dynamic body.
+type Controller struct {
+	*physics.CharacterController
+}
+
+// PlayerInput marks the entity driven by the player's input actions.
+type PlayerInput struct{}
+
+// Health is the hit points of an entity. Entities other than the player are destroyed when they run out.
+type Health struct {
+	Current, Max int32
+}
+
+// Damage removes hit points, clamped to zero, and returns true if the entity ran out.
+func (h *Health) Damage(amount int32) bool {
+	h.Current = max(h.Current-amount, 0)
+	return h.Current == 0
+}
+
+// Heal restores hit points, clamped to the maximum.
+func (h *Health) Heal(amount int32) {
+	h.Current = min(h.Current+amount, h.Max)
+}
+
+// IsDead returns true if the entity ran out of hit points.
+func (h *Health) IsDead() bool {
+	return h.Current <= 0
+}
+
+// registerComponents creates the component storages of the level and their cleanup hooks.
+func (l *Level) registerComponents() {
+	ecs.Components[Collider](l.entities).OnRemove(func(e ecs.Entity, c *Collider) {
+		l.world.RemoveCollider(c.Collider)
+		l.world.Pool().Release(c.Collider)
+	})
+}
@@ -0,0 +1,196 @@
+package level
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/adm87/deepdown/scripts/assets"
+	"github.com/adm87/deepdown/scripts/ecs"
+	"github.com/adm87/deepdown/scripts/physics"
+	"github.com/adm87/tiled"
+	"github.com/adm87/tiled/tilemap"
+)
+
+// EntityObject is a Tiled object spawning an entity, along with the attributes the tiled package does not decode.
+type EntityObject struct {
+	*tiled.Object
+
+	Group    string  // Name of the object group holding the object
+	Class    string  // Class the entity was registered under
+	Name     string  // Name of the object
+	Rotation float32 // Clockwise rotation in degrees around the object's position
+}
+
+// TopLeft returns the top-left corner of the object, accounting for tile objects being positioned by their bottom-left corner.
+func (obj *EntityObject) TopLeft() (x, y float32) {
+	if obj.GID != 0 {
+		return obj.X, obj.Y - obj.Height
+	}
+	return obj.X, obj.Y
+}
+
+// EntitySpawner builds the entity of an object when a level is set.
+type EntitySpawner func(l *Level, obj *EntityObject) error
+
+var spawners = make(map[string]EntitySpawner)
+
+// RegisterSpawner registers the spawner building objects of a Tiled class, replacing any spawner registered before.
+// Spawners must be registered before a level is set to take effect.
+func RegisterSpawner(class string, spawn EntitySpawner) {
+	spawners[class] = spawn
+}
+
+// RegisterEntity registers a spawner receiving the object's custom properties decoded into a copy of defaults.
+// See DecodeProperties for how properties are matched to fields.
+func RegisterEntity[T any](class string, defaults T, spawn func(l *Level, obj *EntityObject, props T) error) {
+	RegisterSpawner(class, func(l *Level, obj *EntityObject) error {
+		props := defaults
+		if err := DecodeProperties(obj.Properties, &props); err != nil {
+			return err
+		}
+		return spawn(l, obj, props)
+	})
+}
+
+// SpawnEntities walks every object group of a tilemap and spawns the objects of registered classes.
+// Objects without a class are left to the collision builders, and objects of unknown classes are skipped with a warning.
+func (l *Level) SpawnEntities(tm *assets.Tilemap) error {
+	for i := range tm.ObjectGroups {
+		group := &tm.ObjectGroups[i]
+
+		for j := range group.Objects {
+			obj := &group.Objects[j]
+
+			attrs := tm.Object(obj.ID)
+			if attrs.Class == "" {
+				continue
+			}
+
+			spawn, ok := spawners[attrs.Class]
+			if !ok {
+				l.ctx.Logger().Warn("Unknown entity class", slog.String("class", attrs.Class), slog.Int("id", int(obj.ID)))
+				continue
+			}
+
+			entity := &EntityObject{
+				Object:   obj,
+				Group:    group.Name,
+				Class:    attrs.Class,
+				Name:     attrs.Name,
+				Rotation: attrs.Rotation,
+			}
+			if err := spawn(l, entity); err != nil {
+				return fmt.Errorf("%s %d: %w", attrs.Class, obj.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ========== Built-in entities ==========
+
+// PlayerProperties are the custom properties of a Player object.
+type PlayerProperties struct {
+	Body      physics.BodyConfig
+	Material  physics.Material
+	Character physics.CharacterConfig
+	Layer     physics.Layer `property:"CollisionLayer"`
+	Health    int32
+}
+
+// CrateProperties are the custom properties of a Crate object.
+type CrateProperties struct {
+	Body     physics.BodyConfig
+	Material physics.Material
+	Layer    physics.Layer `property:"CollisionLayer"`
+}
+
+func init() {
+	RegisterEntity("Player", PlayerProperties{
+		Body:      physics.DefaultBodyConfig(),
+		Material:  physics.DefaultMaterial(),
+		Character: physics.DefaultCharacterConfig(),
+		Health:    3,
+	}, spawnPlayer)
+
+	RegisterEntity("Crate", CrateProperties{
+		Body:     physics.DefaultBodyConfig(),
+		Material: physics.DefaultMaterial(),
+	}, spawnCrate)
+}
+
+func spawnPlayer(l *Level, obj *EntityObject, props PlayerProperties) error {
+	if l.player != ecs.NoEntity {
+		l.ctx.Logger().Warn("Skipping extra player spawn", slog.Int("id", int(obj.ID)))
+		return nil
+	}
+	if props.Body.Mass <= 0 {
+		return fmt.Errorf("invalid body mass: %f", props.Body.Mass)
+	}
+	if props.Health <= 0 {
+		return fmt.Errorf("invalid health: %d", props.Health)
+	}
+
+	width, height := obj.Width*0.5, obj.Height*0.7
+
+	collider := l.world.Pool().GetBoxCollider(obj.X, obj.Y, width, height)
+	collider.Info().State = physics.ColliderStateDynamic
+	collider.Info().Body = props.Body
+	collider.Info().Material = props.Material
+	collider.Info().Layer = props.Layer
+	collider.Info().Offset[0] = (obj.Width - width) * 0.5
+	collider.Info().Offset[1] = obj.Height - height
+
+	controller := physics.NewCharacterController(collider, props.Character)
+	l.world.AddCharacter(controller)
+
+	l.player = l.entities.Create()
+	ecs.Add(l.entities, l.player, Transform{X: obj.X, Y: obj.Y})
+	ecs.Add(l.entities, l.player, Collider{collider})
+	ecs.Add(l.entities, l.player, Controller{controller})
+	ecs.Add(l.entities, l.player, PlayerInput{})
+	ecs.Add(l.entities, l.player, Health{Current: props.Health, Max: props.Health})
+
+	if !l.addSprite(l.player, obj.GID, obj.X, obj.Y) {
+		l.ctx.Logger().Warn("No tile data found for player spawn")
+	}
+
+	l.ctx.Logger().Info("Player spawn created at ", obj.X, ", ", obj.Y)
+	return nil
+}
+
+// spawnCrate builds a dynamic box covering the object, drawn with the object's tile when it is a tile object.
+func spawnCrate(l *Level, obj *EntityObject, props CrateProperties) error {
+	if props.Body.Mass <= 0 {
+		return fmt.Errorf("invalid body mass: %f", props.Body.Mass)
+	}
+
+	x, y := obj.TopLeft()
+	collider := l.world.Pool().GetBoxCollider(x, y, obj.Width, obj.Height)
+	collider.Info().State = physics.ColliderStateDynamic
+	collider.Info().Body = props.Body
+	collider.Info().Material = props.Material
+	collider.Info().Layer = props.Layer
+
+	l.world.AddCollider(collider)
+
+	e := l.entities.Create()
+	ecs.Add(l.entities, e, Transform{X: x, Y: y})
+	ecs.Add(l.entities, e, Collider{collider})
+
+	if obj.GID != 0 && !l.addSprite(e, obj.GID, x, y) {
+		l.ctx.Logger().Warn("No tile data found for crate", slog.Int("id", int(obj.ID)))
+	}
+	return nil
+}
+
+// addSprite adds a Sprite drawing the tile of a GID to an entity.
+// It returns false if the GID has no tile data.
+func (l *Level) addSprite(e ecs.Entity, gid uint32, x, y float32) bool {
+	data, ok := tilemap.GetTileData(gid, l.tilemap.Tmx, x, y)
+	if !ok {
+		return false
+	}
+	ecs.Add(l.entities, e, Sprite{Data: data})
+	return true
+}
@@ -0,0 +1,85 @@
+package level
+
+import (
+	"fmt"
+
+	"github.com/adm87/deepdown/scripts/assets"
+	"github.com/adm87/deepdown/scripts/physics"
+	"github.com/adm87/tiled"
+)
+
+const (
+	collisionLayerType  = "CollisionLayer"  // Enum listing the collision layers, Default first
+	collisionMatrixProp = "CollisionMatrix" // Class property holding the layers each layer collides with
+)
+
+// LoadCollisionLayers replaces the physics collision layers with the ones declared in the Tiled project.
+//
+// Layers are the values of the CollisionLayer enum, in order, and must start with Default.
+// The CollisionMatrix project property lists, for each layer, the layers it collides with.
+// Collision is symmetric, so listing a pair under either layer enables it.
+// Without a CollisionMatrix property, Default collides with every layer and the others with nothing else.
+func LoadCollisionLayers(project *assets.Project) error {
+	physics.ResetLayers()
+
+	layerType, ok := project.PropertyType(collisionLayerType)
+	if !ok {
+		return nil
+	}
+	if len(layerType.Values) == 0 || layerType.Values[0] != physics.CollisionLayerDefault.String() {
+		return fmt.Errorf("%s enum must start with %s", collisionLayerType, physics.CollisionLayerDefault)
+	}
+	if len(layerType.Values) > physics.MaxCollisionLayers {
+		return fmt.Errorf("%s enum declares %d layers, the maximum is %d", collisionLayerType, len(layerType.Values), physics.MaxCollisionLayers)
+	}
+
+	for _, name := range layerType.Values[1:] {
+		physics.NewLayer(name)
+	}
+
+	matrix, ok := project.Property(collisionMatrixProp)
+	if !ok {
+		return nil
+	}
+	masks, err := project.ClassValues(matrix)
+	if err != nil {
+		return err
+	}
+
+	physics.ClearCollisionMatrix()
+	for name, value := range masks {
+		layer, ok := physics.LayerByName(name)
+		if !ok {
+			return fmt.Errorf("%s: unknown collision layer %s", collisionMatrixProp, name)
+		}
+
+		others, err := assets.EnumFlags(value)
+		if err != nil {
+			return fmt.Errorf("%s: layer %s: %w", collisionMatrixProp, name, err)
+		}
+
+		for _, otherName := range others {
+			other, ok := physics.LayerByName(otherName)
+			if !ok {
+				return fmt.Errorf("%s: layer %s: unknown collision layer %s", collisionMatrixProp, name, otherName)
+			}
+			physics.EnableCollision(layer, other)
+		}
+	}
+
+	return nil
+}
+
+// collisionLayer returns the layer named by the CollisionLayer property, or Default if it is not set.
+func collisionLayer(properties []tiled.Property) (physics.Layer, error) {
+	prop := propertyByName(properties, collisionLayerType)
+	if prop == nil || prop.Value == "" {
+		return physics.CollisionLayerDefault, nil
+	}
+
+	layer, ok := physics.LayerByName(prop.Value)
+	if !ok {
+		return physics.CollisionLayerDefault, fmt.Errorf("unknown collision layer: %s", prop.Value)
+	}
+	return layer, nil
+}
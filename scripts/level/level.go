@@ -10,8 +10,7 @@ import (
 	"github.com/adm87/deepdown/scripts/camera"
 	"github.com/adm87/deepdown/scripts/debug"
 	"github.com/adm87/deepdown/scripts/deepdown"
-	"github.com/adm87/deepdown/scripts/input"
-	"github.com/adm87/deepdown/scripts/input/actions"
+	"github.com/adm87/deepdown/scripts/ecs"
 	"github.com/adm87/deepdown/scripts/physics"
 	"github.com/adm87/tiled"
 	"github.com/adm87/tiled/tilemap"
@@ -21,100 +20,198 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
-const CoyoteTime float32 = 0.1
-
-type Player struct {
-	physics.BoxCollider
-
-	Data tilemap.Data
-}
-
-func (p *Player) CanJump() bool {
-	info := p.Info()
-	return info.TimeSinceLeftGround() <= CoyoteTime
-}
+const (
+	CoyoteTime       float32 = 0.1
+	DefaultPathSpeed float32 = 20.0
+)
 
 type Level struct {
 	ctx deepdown.Context
 
-	tilemap *tilemap.Map
-	camera  *camera.Camera
-	player  *Player
+	tilemap   *tilemap.Map
+	camera    *camera.Camera
+	source    *assets.Tilemap
+	stream    streamState
+	animation animationState
+	markers   []Marker
+	warp      *Warp // Warp requested by a portal, taken by the level manager
 
-	world *physics.World
+	world    *physics.World
+	entities *ecs.World
+	player   ecs.Entity
 
 	op ebiten.DrawImageOptions
 }
 
 func NewLevel(ctx deepdown.Context, targetWidth, targetHeight float32) *Level {
-	world := physics.NewWorld(ctx)
-	return &Level{
-		ctx:     ctx,
-		tilemap: tilemap.NewMap(),
-		camera:  camera.NewCamera(0, 0, targetWidth, targetHeight),
-		op:      ebiten.DrawImageOptions{},
-		world:   world,
+	world := physics.NewWorld(ctx, physics.DefaultConfig())
+	l := &Level{
+		ctx:      ctx,
+		tilemap:  tilemap.NewMap(),
+		camera:   camera.NewCamera(0, 0, targetWidth, targetHeight),
+		op:       ebiten.DrawImageOptions{},
+		world:    world,
+		entities: ecs.NewWorld(),
 	}
+	l.registerComponents()
+	l.registerSystems()
+	return l
 }
 
 func (l *Level) Camera() *camera.Camera {
 	return l.camera
 }
 
+// Bounds returns the area covered by the level's tile layers in pixels.
+// Infinite maps cover the chunks painted in any layer, and an unloaded level covers nothing.
 func (l *Level) Bounds() (minX, minY, maxX, maxY int32) {
-	return 0, 0, 0, 0
+	if l.source == nil {
+		return 0, 0, 0, 0
+	}
+	minX, minY, maxX, maxY = l.source.TileBounds()
+	tileWidth, tileHeight := l.source.TileWidth, l.source.TileHeight
+	return minX * tileWidth, minY * tileHeight, maxX * tileWidth, maxY * tileHeight
+}
+
+// World returns the physics world of the level.
+func (l *Level) World() *physics.World {
+	return l.world
+}
+
+// Entities returns the entities of the level, where game code may add its own components and systems.
+func (l *Level) Entities() *ecs.World {
+	return l.entities
+}
+
+// Player returns the entity driven by the player's input, or ecs.NoEntity if the level has none.
+func (l *Level) Player() ecs.Entity {
+	return l.player
+}
+
+// Tilemap returns the tilemap the level was built from, or nil if it is unloaded.
+func (l *Level) Tilemap() *assets.Tilemap {
+	return l.source
+}
+
+// PhysicsConfig returns the physics config declared by the map properties of a tilemap.
+func PhysicsConfig(tm *assets.Tilemap) (physics.Config, error) {
+	return physicsConfig(tm.Properties)
+}
+
+// SetTmx builds the level from a tilemap using the physics config declared by the map.
+func (l *Level) SetTmx(tm *assets.Tilemap) error {
+	config, err := PhysicsConfig(tm)
+	if err != nil {
+		return err
+	}
+	return l.SetTmxWithConfig(tm, config)
+}
+
+// Unload destroys the level's entities and returns every collider to the physics world's pool.
+// The level is empty until a tilemap is set again.
+func (l *Level) Unload() {
+	l.unload(l.world.Config())
 }
 
-func (l *Level) SetTmx(tmx *tiled.Tmx) error {
+// unload empties the level, resetting the physics world with the given config.
+func (l *Level) unload(config physics.Config) {
+	l.entities.Clear()
+	l.world.Reset(config)
+
+	l.source = nil
+	l.stream = streamState{}
+	l.animation.clock = 0
+	l.player = ecs.NoEntity
+	l.markers = nil
+	l.warp = nil
+}
+
+// SetTmxWithConfig builds the level from a tilemap using the given physics config.
+// The previous level is unloaded first, see Unload. Map properties are validated before unloading,
+// but an error while building leaves the level partially built.
+func (l *Level) SetTmxWithConfig(tm *assets.Tilemap, config physics.Config) error {
+	tmx := tm.Tmx
+
+	stream, err := streamConfig(tm)
+	if err != nil {
+		return err
+	}
+
+	l.unload(config)
+	l.source = tm
+
 	l.tilemap.SetTmx(tmx)
 	l.tilemap.Frame().Set(l.camera.Viewport())
 
-	if err := l.BuildStaticCollision(tiled.ObjectGroupByName(tmx, "Floors")); err != nil {
+	l.stream = streamState{config: stream, chunks: make(map[[2]int32]*streamChunk)}
+
+	// Streamed tile collision is built once the camera is placed on the player
+	if !stream.Enabled {
+		if err := l.BuildTileCollision(tm); err != nil {
+			return err
+		}
+	}
+
+	if err := l.BuildStaticCollision(tm, tiled.ObjectGroupByName(tmx, "Floors")); err != nil {
 		return err
 	}
 
-	if err := l.BuildStaticCollision(tiled.ObjectGroupByName(tmx, "Static")); err != nil {
+	if err := l.BuildStaticCollision(tm, tiled.ObjectGroupByName(tmx, "Static")); err != nil {
 		return err
 	}
 
-	if err := l.BuildPlayer(tiled.ObjectGroupByName(tmx, "Player"), tmx); err != nil {
+	if err := l.BuildKinematics(tiled.ObjectGroupByName(tmx, "Platforms")); err != nil {
 		return err
 	}
 
-	l.clampCamera()
-	return nil
+	if err := l.SpawnEntities(tm); err != nil {
+		return err
+	}
+
+	if l.player == ecs.NoEntity {
+		l.ctx.Logger().Warn("No player spawn object found")
+	}
+
+	l.follow(1)
+	return l.streamChunks()
 }
 
 func (l *Level) Update(dts float64) {
-	if input.IsActive(actions.MoveLeft) {
-		l.player.Velocity[0] -= actions.MovementSpeed
-	}
-	if input.IsActive(actions.MoveRight) {
-		l.player.Velocity[0] += actions.MovementSpeed
-	}
-	if jump := input.GetBinding[*input.KeyPressDurationBinding](actions.Jump); jump != nil {
-		if l.player.CanJump() && jump.JustReleased() {
-			pressure := jump.Pressure()
-			l.player.Velocity[1] = actions.JumpVelocity * float32(pressure)
-			l.player.OnGround = false
-		}
-	}
+	l.entities.Run(ecs.PhaseUpdate, dts)
 }
 
 func (l *Level) FixedUpdate(dt float64) {
-	minX, minY, maxX, maxY := l.camera.Viewport()
-	l.world.Update(dt, minX, minY, maxX, maxY)
+	l.entities.Run(ecs.PhaseFixedUpdate, dt)
 }
 
 func (l *Level) LateUpdate(dt float64) {
-	l.camera.X = l.player.X + l.player.Width/2
-	l.camera.Y = l.player.Y + l.player.Height/2
-	l.player.Data.X = l.player.X
-	l.player.Data.Y = l.player.Y
+	l.entities.Run(ecs.PhaseLateUpdate, dt)
+}
+
+// follow centers the camera on the player and places sprites at their render positions.
+// Alpha is the fraction of a fixed step accumulated since the last step.
+func (l *Level) follow(alpha float32) {
+	ecs.Each2(l.entities, func(e ecs.Entity, s *Sprite, t *Transform) {
+		s.Data.X, s.Data.Y = t.X, t.Y
+		if c := ecs.Get[Collider](l.entities, e); c != nil {
+			s.Data.X, s.Data.Y = l.world.RenderPosition(c.Collider, alpha)
+		}
+	})
+
+	if c := ecs.Get[Collider](l.entities, l.player); c != nil {
+		x, y := l.world.RenderPosition(c.Collider, alpha)
+		minX, minY, maxX, maxY := c.AABB()
+		l.camera.X = x + (maxX-minX)/2
+		l.camera.Y = y + (maxY-minY)/2
+	}
 	l.clampCamera()
 }
 
-func (l *Level) Draw(screen *ebiten.Image) {
+// Draw renders the level with moving colliders interpolated by alpha, the fraction of a fixed step
+// accumulated since the last step, so motion stays smooth when the tick rate differs from the fixed rate.
+func (l *Level) Draw(screen *ebiten.Image, alpha float32) {
+	l.follow(alpha)
+
 	screen.Fill(color.RGBA{R: 30, G: 30, B: 30, A: 255})
 
 	l.tilemap.Frame().Set(l.camera.Viewport())
@@ -124,10 +221,17 @@ func (l *Level) Draw(screen *ebiten.Image) {
 	itr := l.tilemap.Itr()
 
 	if debug.DrawTilemap {
-		for tiles := itr.Next(); tiles != nil; tiles = itr.Next() {
-			l.DrawTileBatch(screen, tiles, mat)
+		if l.source != nil && l.source.Infinite {
+			l.drawTileLayers(screen, mat)
+		} else {
+			for tiles := itr.Next(); tiles != nil; tiles = itr.Next() {
+				l.DrawTileBatch(screen, tiles, mat)
+			}
 		}
-		l.DrawTile(&l.player.Data, screen, mat)
+		l.DrawKinematics(screen, mat, l.world.QueryKinematic(l.camera.Viewport()), alpha, color.RGBA{R: 120, G: 100, B: 80, A: 255})
+		ecs.Each(l.entities, func(e ecs.Entity, s *Sprite) {
+			l.drawTile(&s.Data, e.Index(), screen, mat)
+		})
 	}
 
 	if debug.DrawCollisionCells {
@@ -137,10 +241,22 @@ func (l *Level) Draw(screen *ebiten.Image) {
 
 	if debug.DrawPotentialCollisions {
 		l.DrawPotentialCollisions(screen, mat, l.world.QueryStatic(l.camera.Viewport()), color.RGBA{B: 255, A: 255})
-		l.DrawPotentialCollisions(screen, mat, l.world.QueryBody(l.player.AABB()), color.RGBA{R: 255, G: 255, A: 255})
+		l.DrawPotentialCollisions(screen, mat, l.world.QueryKinematic(l.camera.Viewport()), color.RGBA{R: 255, B: 255, A: 255})
+		l.DrawPotentialCollisions(screen, mat, l.world.QueryVolume(l.camera.Viewport()), color.RGBA{G: 180, B: 255, A: 255})
+		l.DrawConstraints(screen, mat, l.world.Constraints(), color.RGBA{R: 255, G: 160, A: 255})
+		if c := ecs.Get[Collider](l.entities, l.player); c != nil {
+			l.DrawPotentialCollisions(screen, mat, l.world.QueryBody(c.AABB()), color.RGBA{R: 255, G: 255, A: 255})
+		}
+	}
+
+	if c := ecs.Get[Collider](l.entities, l.player); c != nil {
+		info := c.Info()
+		ebitenutil.DebugPrint(screen, fmt.Sprintf("Vel: %.2f, %.2f\nOnGround: %v", info.Velocity[0], info.Velocity[1], info.OnGround))
 	}
 
-	ebitenutil.DebugPrint(screen, fmt.Sprintf("Vel: %.2f, %.2f\nOnGround: %v", l.player.Velocity[0], l.player.Velocity[1], l.player.OnGround))
+	if debug.DrawPhysicsStats {
+		ebitenutil.DebugPrintAt(screen, l.world.Stats().String(), 0, 32)
+	}
 }
 
 func (l *Level) DrawTileBatch(screen *ebiten.Image, tiles []tilemap.Data, mat ebiten.GeoM) {
@@ -149,38 +265,91 @@ func (l *Level) DrawTileBatch(screen *ebiten.Image, tiles []tilemap.Data, mat eb
 	}
 }
 
+// DrawTile draws a tile of the map, showing the current frame of animated tiles.
 func (l *Level) DrawTile(data *tilemap.Data, screen *ebiten.Image, mat ebiten.GeoM) {
+	tileWidth, tileHeight := float32(l.tilemap.Tmx.TileWidth), float32(l.tilemap.Tmx.TileHeight)
+	seed := cellSeed(int32(math.Floor(float64(data.X/tileWidth))), int32(math.Floor(float64(data.Y/tileHeight))))
+	l.drawTile(data, seed, screen, mat)
+}
+
+// drawTile draws a tile with the animation seed of its instance, see TileAnimationOffset.
+func (l *Level) drawTile(data *tilemap.Data, seed uint32, screen *ebiten.Image, mat ebiten.GeoM) {
 	tileset, err := l.tilemap.GetTileset(data.TsIdx)
 	if err != nil {
 		println(err.Error())
 		return
 	}
 
-	tsx := assets.MustGet[*tiled.Tsx](assets.AssetHandle(tileset.Source))
+	tsx := assets.MustGet[*assets.Tileset](assets.AssetHandle(tileset.Source))
+	l.drawTileImage(screen, tsx, l.tileFrame(tsx, data.TileID, seed), data.X, data.Y, data.FlipFlag.Diagonal(), data.FlipFlag.Horizontal(), data.FlipFlag.Vertical(), mat)
+}
+
+// DrawGID draws the tile of a GID, including its flip flags and current animation frame, with the tile's bottom-left corner at the bottom-left of the map cell at x, y.
+func (l *Level) DrawGID(screen *ebiten.Image, gid uint32, x, y float32, mat ebiten.GeoM) {
+	ts, ok := l.source.TilesetByGID(gid)
+	if !ok {
+		return
+	}
+	tsx, ok := assets.Get[*assets.Tileset](ts.Source)
+	if !ok {
+		return
+	}
+
+	tileID := (gid &^ assets.GIDFlipMask) - ts.FirstGID
+	diagonal := gid&assets.GIDFlipDiagonal != 0
+	horizontal := gid&assets.GIDFlipHorizontal != 0
+	vertical := gid&assets.GIDFlipVertical != 0
+	seed := cellSeed(int32(math.Floor(float64(x/float32(l.source.TileWidth)))), int32(math.Floor(float64(y/float32(l.source.TileHeight)))))
+	l.drawTileImage(screen, tsx, l.tileFrame(tsx, tileID, seed), x, y, diagonal, horizontal, vertical, mat)
+}
+
+// drawTileLayers draws the tiles of every tile layer within the camera's viewport straight from the layer chunks,
+// used for infinite maps that the tilemap renderer does not cover.
+func (l *Level) drawTileLayers(screen *ebiten.Image, mat ebiten.GeoM) {
+	tileWidth, tileHeight := float32(l.source.TileWidth), float32(l.source.TileHeight)
+
+	// Tiles taller or wider than the map grid may reach into the viewport from a neighboring cell
+	minX, minY, maxX, maxY := l.camera.Viewport()
+	x0, y0 := int32(math.Floor(float64(minX/tileWidth)))-1, int32(math.Floor(float64(minY/tileHeight)))-1
+	x1, y1 := int32(math.Floor(float64(maxX/tileWidth)))+1, int32(math.Floor(float64(maxY/tileHeight)))+1
+
+	for i := range l.source.TileLayers {
+		layer := &l.source.TileLayers[i]
+		for y := max(y0, layer.Y); y <= min(y1, layer.Y+layer.Height-1); y++ {
+			for x := max(x0, layer.X); x <= min(x1, layer.X+layer.Width-1); x++ {
+				if gid := layer.GID(x, y); gid != 0 {
+					l.DrawGID(screen, gid, float32(x)*tileWidth, float32(y)*tileHeight, mat)
+				}
+			}
+		}
+	}
+}
+
+func (l *Level) drawTileImage(screen *ebiten.Image, tsx *assets.Tileset, tileID uint32, x, y float32, diagonal, horizontal, vertical bool, mat ebiten.GeoM) {
 	img := assets.MustGet[*ebiten.Image](assets.AssetHandle(tsx.Image.Source))
 
-	srcX := (int32(data.TileID) % tsx.Columns) * tsx.TileWidth
-	srcY := (int32(data.TileID) / tsx.Columns) * tsx.TileHeight
+	srcX := (int32(tileID) % tsx.Columns) * tsx.TileWidth
+	srcY := (int32(tileID) / tsx.Columns) * tsx.TileHeight
 	srcRect := image.Rect(int(srcX), int(srcY), int(srcX+tsx.TileWidth), int(srcY+tsx.TileHeight))
 
-	distX := float64(data.X) + float64(tsx.TileOffset.X)
-	distY := float64(data.Y) + float64(tsx.TileOffset.Y)
+	distX := float64(x) + float64(tsx.TileOffset.X)
+	distY := float64(y) + float64(tsx.TileOffset.Y)
 	distY -= float64(tsx.TileHeight) - float64(l.tilemap.Tmx.TileHeight) // Align to bottom of tile
 
 	l.op.GeoM.Reset()
 
-	if data.FlipFlag.Diagonal() {
+	if diagonal {
 		l.op.GeoM.Rotate(math.Pi * 0.5)
 		l.op.GeoM.Scale(-1, 1)
 		l.op.GeoM.Translate(float64(tsx.TileHeight-tsx.TileWidth), 0)
 	}
 
-	if data.FlipFlag.Horizontal() {
+	if horizontal {
 		l.op.GeoM.Scale(-1, 1)
 		l.op.GeoM.Translate(float64(tsx.TileWidth), 0)
 	}
 
-	if data.FlipFlag.Vertical() {
+	if vertical {
 		l.op.GeoM.Scale(1, -1)
 		l.op.GeoM.Translate(0, float64(tsx.TileHeight))
 	}
@@ -191,8 +360,47 @@ func (l *Level) DrawTile(data *tilemap.Data, screen *ebiten.Image, mat ebiten.Ge
 	screen.DrawImage(img.SubImage(srcRect).(*ebiten.Image), &l.op)
 }
 
+func (l *Level) DrawKinematics(screen *ebiten.Image, mat ebiten.GeoM, colliders []physics.Collider, alpha float32, col color.RGBA) {
+	for i := range colliders {
+		cMinX, cMinY, cMaxX, cMaxY := colliders[i].AABB()
+
+		// Offset the bounds from the collider's position to its render position
+		x, y := colliders[i].Position()
+		renderX, renderY := l.world.RenderPosition(colliders[i], alpha)
+		cMinX, cMaxX = cMinX+renderX-x, cMaxX+renderX-x
+		cMinY, cMaxY = cMinY+renderY-y, cMaxY+renderY-y
+
+		minX, minY := mat.Apply(float64(cMinX), float64(cMinY))
+		maxX, maxY := mat.Apply(float64(cMaxX), float64(cMaxY))
+
+		vector.DrawFilledRect(screen, float32(minX), float32(minY), float32(maxX-minX), float32(maxY-minY), col, false)
+	}
+}
+
+func (l *Level) DrawConstraints(screen *ebiten.Image, mat ebiten.GeoM, constraints []*physics.Constraint, col color.RGBA) {
+	path := vector.Path{}
+
+	for _, c := range constraints {
+		aX, aY := c.A.Point()
+		bX, bY := c.B.Point()
+
+		minX, minY := mat.Apply(float64(aX), float64(aY))
+		maxX, maxY := mat.Apply(float64(bX), float64(bY))
+
+		path.MoveTo(float32(minX), float32(minY))
+		path.LineTo(float32(maxX), float32(maxY))
+	}
+
+	op := &vector.DrawPathOptions{}
+	op.ColorScale.ScaleWithColor(col)
+
+	vector.StrokePath(screen, &path, &vector.StrokeOptions{
+		Width: 1,
+	}, op)
+}
+
 func (l *Level) DrawCollisionCells(screen *ebiten.Image, mat ebiten.GeoM, cells []uint64, col color.RGBA) {
-	width, height := physics.GridCellSize, physics.GridCellSize
+	width, height := l.world.Config().GridCellSize, l.world.Config().GridCellSize
 	path := vector.Path{}
 
 	for i := range cells {
@@ -259,6 +467,37 @@ func (l *Level) DrawPotentialCollisions(screen *ebiten.Image, mat ebiten.GeoM, c
 
 			path.MoveTo(float32(startX64), float32(startY64))
 			path.LineTo(float32(endX64), float32(endY64))
+
+		case *physics.PolygonCollider:
+			poly := c.(*physics.PolygonCollider)
+			for i := 0; i <= poly.VertexCount(); i++ {
+				x, y := poly.GetVertex(i % poly.VertexCount())
+				x64, y64 := mat.Apply(float64(x), float64(y))
+				if i == 0 {
+					path.MoveTo(float32(x64), float32(y64))
+				} else {
+					path.LineTo(float32(x64), float32(y64))
+				}
+			}
+
+		case *physics.CircleCollider:
+			circle := c.(*physics.CircleCollider)
+			x64, y64 := mat.Apply(float64(circle.X), float64(circle.Y))
+			path.MoveTo(float32(x64)+circle.Radius, float32(y64))
+			path.Arc(float32(x64), float32(y64), circle.Radius, 0, 2*math.Pi, vector.Clockwise)
+
+		case *physics.CapsuleCollider:
+			capsule := c.(*physics.CapsuleCollider)
+			start, end := capsule.Segment()
+			for _, p := range [2][2]float32{start, end} {
+				x64, y64 := mat.Apply(float64(p[0]), float64(p[1]))
+				path.MoveTo(float32(x64)+capsule.Radius, float32(y64))
+				path.Arc(float32(x64), float32(y64), capsule.Radius, 0, 2*math.Pi, vector.Clockwise)
+			}
+			startX64, startY64 := mat.Apply(float64(start[0]), float64(start[1]))
+			endX64, endY64 := mat.Apply(float64(end[0]), float64(end[1]))
+			path.MoveTo(float32(startX64), float32(startY64))
+			path.LineTo(float32(endX64), float32(endY64))
 		}
 	}
 
@@ -271,11 +510,10 @@ func (l *Level) DrawPotentialCollisions(screen *ebiten.Image, mat ebiten.GeoM, c
 }
 
 func (l *Level) clampCamera() {
-	maxX := l.tilemap.Tmx.Width * l.tilemap.Tmx.TileWidth
-	maxY := l.tilemap.Tmx.Height * l.tilemap.Tmx.TileHeight
+	minX, minY, maxX, maxY := l.Bounds()
 
-	l.camera.X = float32(math.Max(float64(l.camera.Width)/2, float64(l.camera.X)))
-	l.camera.Y = float32(math.Max(float64(l.camera.Height)/2, float64(l.camera.Y)))
+	l.camera.X = float32(math.Max(float64(minX)+float64(l.camera.Width)/2, float64(l.camera.X)))
+	l.camera.Y = float32(math.Max(float64(minY)+float64(l.camera.Height)/2, float64(l.camera.Y)))
 	l.camera.X = float32(math.Min(float64(maxX)-float64(l.camera.Width)/2, float64(l.camera.X)))
 	l.camera.Y = float32(math.Min(float64(maxY)-float64(l.camera.Height)/2, float64(l.camera.Y)))
 }
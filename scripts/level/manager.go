@@ -0,0 +1,218 @@
+package level
+
+import (
+	"image/color"
+	"log/slog"
+
+	"github.com/adm87/deepdown/scripts/assets"
+	"github.com/adm87/deepdown/scripts/deepdown"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// TransitionConfig configures the fade covering a level swap.
+type TransitionConfig struct {
+	FadeOut float32    // Seconds to fade to the color before the swap
+	FadeIn  float32    // Seconds to fade back from the color after the swap
+	Color   color.RGBA // Color covering the screen during the swap
+}
+
+// DefaultTransitionConfig returns a short fade through black.
+func DefaultTransitionConfig() TransitionConfig {
+	return TransitionConfig{
+		FadeOut: 0.3,
+		FadeIn:  0.3,
+		Color:   color.RGBA{A: 255},
+	}
+}
+
+type transitionState uint8
+
+const (
+	transitionIdle      transitionState = iota
+	transitionFadingOut                 // The level is frozen while the screen fades out
+	transitionFadingIn                  // The new level runs while the screen fades in
+)
+
+// Manager owns the current level and switches maps when a door or warp is used.
+//
+// A warp fades the screen out, unloads the old level, returning its colliders to the physics pool,
+// loads the target map and places the player at the target spawn point with the state it had before
+// the swap, then fades back in.
+type Manager struct {
+	ctx deepdown.Context
+
+	level      *Level
+	transition TransitionConfig
+
+	state   transitionState
+	elapsed float32
+	warp    Warp
+}
+
+// NewManager returns a manager with an empty level rendering to a target of the given size.
+func NewManager(ctx deepdown.Context, targetWidth, targetHeight float32) *Manager {
+	return &Manager{
+		ctx:        ctx,
+		level:      NewLevel(ctx, targetWidth, targetHeight),
+		transition: DefaultTransitionConfig(),
+	}
+}
+
+// Level returns the current level.
+func (m *Manager) Level() *Level {
+	return m.level
+}
+
+// SetTransition sets the fade used by the following level swaps.
+func (m *Manager) SetTransition(config TransitionConfig) {
+	m.transition = config
+}
+
+// Transitioning returns true while a level swap is fading out or in.
+func (m *Manager) Transitioning() bool {
+	return m.state != transitionIdle
+}
+
+// Load loads a tilemap and its tilesets, then builds the level from it without a transition.
+func (m *Manager) Load(handle assets.AssetHandle) error {
+	tm, err := assets.LoadTilemap(handle)
+	if err != nil {
+		return err
+	}
+	return m.level.SetTmx(tm)
+}
+
+// Travel starts a transition to a warp's map and spawn point. It is ignored while a transition is in progress.
+func (m *Manager) Travel(warp Warp) {
+	if m.state != transitionIdle {
+		return
+	}
+	m.warp = warp
+	m.state = transitionFadingOut
+	m.elapsed = 0
+}
+
+func (m *Manager) Update(dt float64) {
+	m.advance(float32(dt))
+	if m.state != transitionFadingOut {
+		m.level.Update(dt)
+	}
+}
+
+func (m *Manager) FixedUpdate(dt float64) {
+	if m.state != transitionFadingOut {
+		m.level.FixedUpdate(dt)
+	}
+}
+
+func (m *Manager) LateUpdate(dt float64) {
+	if m.state == transitionFadingOut {
+		return
+	}
+
+	m.level.LateUpdate(dt)
+	if warp, ok := m.level.TakeWarp(); ok {
+		m.Travel(warp)
+	}
+}
+
+// Draw renders the current level and covers it with the fade of a transition in progress.
+func (m *Manager) Draw(screen *ebiten.Image, alpha float32) {
+	m.level.Draw(screen, alpha)
+
+	var cover float32
+	switch m.state {
+	case transitionFadingOut:
+		cover = fadeProgress(m.elapsed, m.transition.FadeOut)
+	case transitionFadingIn:
+		cover = 1 - fadeProgress(m.elapsed, m.transition.FadeIn)
+	default:
+		return
+	}
+
+	col := m.transition.Color
+	col.R = uint8(float32(col.R) * cover)
+	col.G = uint8(float32(col.G) * cover)
+	col.B = uint8(float32(col.B) * cover)
+	col.A = uint8(float32(col.A) * cover)
+
+	bounds := screen.Bounds()
+	vector.DrawFilledRect(screen, 0, 0, float32(bounds.Dx()), float32(bounds.Dy()), col, false)
+}
+
+// advance steps the transition in progress, swapping levels once the screen is covered.
+func (m *Manager) advance(dt float32) {
+	switch m.state {
+	case transitionFadingOut:
+		m.elapsed += dt
+		if m.elapsed >= m.transition.FadeOut {
+			m.swap()
+			m.state = transitionFadingIn
+			m.elapsed = 0
+		}
+	case transitionFadingIn:
+		m.elapsed += dt
+		if m.elapsed >= m.transition.FadeIn {
+			m.state = transitionIdle
+		}
+	}
+}
+
+// swap moves the player to the warp's map and spawn point, carrying its state across.
+// If the map fails to load, the player stays in the current level, which is rebuilt if the failed load had unloaded it.
+func (m *Manager) swap() {
+	state, hasPlayer := m.level.PlayerState()
+	previous := m.level.Tilemap()
+
+	if m.warp.Map != "" && (previous == nil || m.warp.Map != previous.Handle) {
+		x, y, placed := m.level.PlayerPosition()
+		if err := m.Load(m.warp.Map); err != nil {
+			m.ctx.Logger().Error("Failed to load level", slog.String("map", m.warp.Map.String()), slog.String("error", err.Error()))
+			if m.level.Tilemap() != previous {
+				m.rollback(previous, x, y, placed, state, hasPlayer)
+			}
+			return
+		}
+	}
+
+	if m.warp.Spawn != "" {
+		x, y, ok := m.level.SpawnPoint(m.warp.Spawn)
+		if ok {
+			m.level.PlacePlayer(x, y)
+		} else {
+			m.ctx.Logger().Warn("Spawn point not found", slog.String("spawn", m.warp.Spawn))
+		}
+	}
+
+	if hasPlayer {
+		m.level.RestorePlayerState(state)
+	}
+}
+
+// rollback rebuilds the level the player was in before a failed swap, placing the player back at x, y with its state.
+func (m *Manager) rollback(previous *assets.Tilemap, x, y float32, placed bool, state PlayerState, hasPlayer bool) {
+	if previous == nil {
+		m.level.Unload()
+		return
+	}
+
+	if err := m.level.SetTmx(previous); err != nil {
+		m.ctx.Logger().Error("Failed to restore level", slog.String("map", previous.Handle.String()), slog.String("error", err.Error()))
+		return
+	}
+	if placed {
+		m.level.PlacePlayer(x, y)
+	}
+	if hasPlayer {
+		m.level.RestorePlayerState(state)
+	}
+}
+
+// fadeProgress returns how far a fade of the given duration is after elapsed seconds, from 0 to 1.
+func fadeProgress(elapsed, duration float32) float32 {
+	if duration <= 0 {
+		return 1
+	}
+	return min(elapsed/duration, 1)
+}
@@ -0,0 +1,202 @@
+package level
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/adm87/deepdown/scripts/assets"
+	"github.com/adm87/deepdown/scripts/geom"
+	"github.com/adm87/deepdown/scripts/physics"
+	"github.com/adm87/tiled"
+)
+
+// EllipseSegments is the number of edges of the polygon approximating an ellipse that is not a circle.
+const EllipseSegments = 16
+
+// Marker is a point object, used to place things in the level without building a collider.
+type Marker struct {
+	ID         int32
+	Name       string
+	X, Y       float32
+	Properties []tiled.Property
+}
+
+// Markers returns the point objects found in the level's collision object groups.
+func (l *Level) Markers() []Marker {
+	return l.markers
+}
+
+// objectColliders builds the colliders covering a collision object, honoring its shape and rotation.
+// Rectangles and polygons are split into boxes, slope triangles and convex polygons, polylines into edges
+// and ellipses into circles. Point objects are recorded as markers and build no colliders.
+func (l *Level) objectColliders(tm *assets.Tilemap, obj *tiled.Object) []physics.Collider {
+	attrs := tm.Object(obj.ID)
+
+	switch objectShape(attrs, obj) {
+	case assets.ObjectShapePoint:
+		l.markers = append(l.markers, Marker{
+			ID:         obj.ID,
+			Name:       attrs.Name,
+			X:          obj.X,
+			Y:          obj.Y,
+			Properties: obj.Properties,
+		})
+		return nil
+
+	case assets.ObjectShapeEllipse:
+		return l.ellipseColliders(obj, attrs.Rotation)
+
+	case assets.ObjectShapePolyline:
+		points := rotatePoints(flatPoints(obj.Polyline.Points), attrs.Rotation)
+		return l.edgeColliders(obj, points)
+
+	case assets.ObjectShapePolygon:
+		points := rotatePoints(flatPoints(obj.Polygon.Points), attrs.Rotation)
+		return l.polygonColliders(obj, obj.X, obj.Y, points)
+
+	case assets.ObjectShapeTile:
+		// Tile objects are positioned by their bottom-left corner
+		points := rotatePoints(rectanglePoints(0, -obj.Height, obj.Width, obj.Height), attrs.Rotation)
+		return l.polygonColliders(obj, obj.X, obj.Y, points)
+
+	default:
+		points := rotatePoints(rectanglePoints(0, 0, obj.Width, obj.Height), attrs.Rotation)
+		return l.polygonColliders(obj, obj.X, obj.Y, points)
+	}
+}
+
+// objectShape returns the shape of an object, falling back to the points decoded by tiled
+// for objects missing from the tilemap's object attributes.
+func objectShape(attrs assets.TilemapObject, obj *tiled.Object) assets.ObjectShape {
+	if attrs.Shape != assets.ObjectShapeRectangle {
+		return attrs.Shape
+	}
+	switch {
+	case len(obj.Polygon.Points) > 0:
+		return assets.ObjectShapePolygon
+	case len(obj.Polyline.Points) > 0:
+		return assets.ObjectShapePolyline
+	default:
+		return assets.ObjectShapeRectangle
+	}
+}
+
+// polygonColliders decomposes a polygon relative to x, y into convex colliders.
+func (l *Level) polygonColliders(obj *tiled.Object, x, y float32, points [][2]float32) []physics.Collider {
+	pieces := geom.DecomposePolygon(points)
+	if len(pieces) == 0 {
+		l.ctx.Logger().Warn("Skipping degenerate collision object", slog.Int("id", int(obj.ID)))
+		return nil
+	}
+
+	colliders := make([]physics.Collider, 0, len(pieces))
+	for _, piece := range pieces {
+		colliders = append(colliders, l.convexCollider(x, y, piece))
+	}
+	return colliders
+}
+
+// convexCollider builds the simplest collider for a convex piece relative to x, y.
+// Axis-aligned rectangles become boxes and right triangles with axis-aligned legs become slopes.
+func (l *Level) convexCollider(x, y float32, piece [][2]float32) physics.Collider {
+	if isAxisAligned(piece, 4, 4) {
+		minX, minY, maxX, maxY := geom.ComputePolygonAABB(piece)
+		return l.world.Pool().GetBoxCollider(x+minX, y+minY, maxX-minX, maxY-minY)
+	}
+
+	if isAxisAligned(piece, 3, 2) {
+		points := [6]float32{
+			piece[0][0], piece[0][1],
+			piece[1][0], piece[1][1],
+			piece[2][0], piece[2][1],
+		}
+		return l.world.Pool().GetTriangleCollider(x, y, points)
+	}
+
+	return l.world.Pool().GetPolygonCollider(x, y, piece)
+}
+
+// isAxisAligned returns true if the piece has the given number of points
+// and at least the given number of horizontal or vertical edges.
+func isAxisAligned(piece [][2]float32, points, edges int) bool {
+	if len(piece) != points {
+		return false
+	}
+
+	aligned := 0
+	for i := range piece {
+		j := (i + 1) % len(piece)
+		if geom.Abs(piece[j][0]-piece[i][0]) <= geom.Epsilon || geom.Abs(piece[j][1]-piece[i][1]) <= geom.Epsilon {
+			aligned++
+		}
+	}
+	return aligned >= edges
+}
+
+// ellipseColliders builds a circle for a round ellipse, or a convex polygon approximating any other ellipse.
+func (l *Level) ellipseColliders(obj *tiled.Object, rotation float32) []physics.Collider {
+	if obj.Width <= 0 || obj.Height <= 0 {
+		l.ctx.Logger().Warn("Skipping degenerate collision object", slog.Int("id", int(obj.ID)))
+		return nil
+	}
+
+	radiusX, radiusY := obj.Width/2, obj.Height/2
+
+	if geom.Abs(radiusX-radiusY) <= geom.Epsilon {
+		center := rotatePoints([][2]float32{{radiusX, radiusY}}, rotation)[0]
+		return []physics.Collider{l.world.Pool().GetCircleCollider(obj.X+center[0], obj.Y+center[1], radiusX)}
+	}
+
+	points := make([][2]float32, EllipseSegments)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / EllipseSegments
+		points[i] = [2]float32{
+			radiusX + radiusX*float32(math.Cos(angle)),
+			radiusY + radiusY*float32(math.Sin(angle)),
+		}
+	}
+	return l.polygonColliders(obj, obj.X, obj.Y, rotatePoints(points, rotation))
+}
+
+// edgeColliders builds a chain of edge colliders along a polyline relative to the object's position.
+func (l *Level) edgeColliders(obj *tiled.Object, points [][2]float32) []physics.Collider {
+	colliders := make([]physics.Collider, 0, len(points))
+	for i := 0; i+1 < len(points); i++ {
+		if points[i] == points[i+1] {
+			continue
+		}
+		colliders = append(colliders, l.world.Pool().GetCapsuleCollider(obj.X, obj.Y, points[i], points[i+1], 0))
+	}
+
+	if len(colliders) == 0 {
+		l.ctx.Logger().Warn("Skipping degenerate collision object", slog.Int("id", int(obj.ID)))
+	}
+	return colliders
+}
+
+// rotatePoints rotates points in place clockwise by degrees around the origin, matching Tiled's object rotation.
+func rotatePoints(points [][2]float32, degrees float32) [][2]float32 {
+	if degrees == 0 {
+		return points
+	}
+
+	sin, cos := math.Sincos(float64(degrees) * math.Pi / 180)
+	for i, p := range points {
+		x, y := float64(p[0]), float64(p[1])
+		points[i] = [2]float32{float32(x*cos - y*sin), float32(x*sin + y*cos)}
+	}
+	return points
+}
+
+func rectanglePoints(x, y, width, height float32) [][2]float32 {
+	return [][2]float32{{x, y}, {x + width, y}, {x + width, y + height}, {x, y + height}}
+}
+
+// flatPoints pairs up the flat x, y coordinates decoded by tiled.
+func flatPoints(coords []float32) [][2]float32 {
+	points := make([][2]float32, 0, len(coords)/2)
+	for i := 0; i+1 < len(coords); i += 2 {
+		points = append(points, [2]float32{coords[i], coords[i+1]})
+	}
+	return points
+}
@@ -0,0 +1,189 @@
+package level
+
+import (
+	"github.com/adm87/deepdown/scripts/assets"
+	"github.com/adm87/deepdown/scripts/ecs"
+	"github.com/adm87/deepdown/scripts/input"
+	"github.com/adm87/deepdown/scripts/input/actions"
+)
+
+// Warp is a request to move the player to a spawn point, usually in another map.
+type Warp struct {
+	Map   assets.AssetHandle // Map to load, or empty to stay in the current map
+	Spawn string             // Name of the object the player is placed at, or empty to keep the map's player spawn
+}
+
+// Portal requests a warp when the player uses it. Doors are used by pressing up while overlapping them
+// and warps as soon as the player overlaps them. A portal the player is placed on is only armed once the
+// player has left it, so arriving on a portal never sends the player straight back.
+type Portal struct {
+	Warp
+
+	Width, Height float32
+	Door          bool
+
+	checked bool // Set once the portal has been checked against the player
+	armed   bool // Set once the player has been outside the portal
+}
+
+// PortalProperties are the custom properties of a Door or Warp object.
+// Map is a file property relative to the map holding the portal.
+type PortalProperties struct {
+	Map   string
+	Spawn string
+}
+
+// PlayerState is the state of the player carried from one level to the next.
+type PlayerState struct {
+	Health   Health
+	Velocity [2]float32
+}
+
+func init() {
+	RegisterEntity("Door", PortalProperties{}, func(l *Level, obj *EntityObject, props PortalProperties) error {
+		return spawnPortal(l, obj, props, true)
+	})
+	RegisterEntity("Warp", PortalProperties{}, func(l *Level, obj *EntityObject, props PortalProperties) error {
+		return spawnPortal(l, obj, props, false)
+	})
+}
+
+func spawnPortal(l *Level, obj *EntityObject, props PortalProperties, door bool) error {
+	warp := Warp{Spawn: props.Spawn}
+	if props.Map != "" {
+		warp.Map = l.source.Handle.Resolve(props.Map)
+	}
+
+	x, y := obj.TopLeft()
+
+	e := l.entities.Create()
+	ecs.Add(l.entities, e, Transform{X: x, Y: y})
+	ecs.Add(l.entities, e, Portal{
+		Warp:   warp,
+		Width:  obj.Width,
+		Height: obj.Height,
+		Door:   door,
+	})
+	return nil
+}
+
+// usePortals requests the warp of the first armed portal the player uses.
+func (l *Level) usePortals(w *ecs.World, dt float64) {
+	c := ecs.Get[Collider](w, l.player)
+	if c == nil {
+		return
+	}
+	minX, minY, maxX, maxY := c.AABB()
+
+	ecs.Each2(w, func(e ecs.Entity, p *Portal, t *Transform) {
+		inside := minX < t.X+p.Width && maxX > t.X && minY < t.Y+p.Height && maxY > t.Y
+
+		if !p.checked {
+			p.checked = true
+			p.armed = !inside
+			return
+		}
+		if !inside {
+			p.armed = true
+			return
+		}
+		if !p.armed || l.warp != nil {
+			return
+		}
+		if p.Door && !input.IsActive(actions.MoveUp) {
+			return
+		}
+
+		warp := p.Warp
+		l.warp = &warp
+		p.armed = false
+	})
+}
+
+// RequestWarp asks the level manager to move the player, replacing any warp requested before.
+func (l *Level) RequestWarp(warp Warp) {
+	l.warp = &warp
+}
+
+// TakeWarp returns and clears the warp requested since the last call.
+func (l *Level) TakeWarp() (Warp, bool) {
+	if l.warp == nil {
+		return Warp{}, false
+	}
+	warp := *l.warp
+	l.warp = nil
+	return warp, true
+}
+
+// SpawnPoint returns the position of the named object, at the bottom center of its area.
+func (l *Level) SpawnPoint(name string) (x, y float32, ok bool) {
+	if l.source == nil {
+		return 0, 0, false
+	}
+
+	for i := range l.source.ObjectGroups {
+		group := &l.source.ObjectGroups[i]
+		for j := range group.Objects {
+			obj := &group.Objects[j]
+			if l.source.Object(obj.ID).Name != name {
+				continue
+			}
+			entity := EntityObject{Object: obj}
+			x, y := entity.TopLeft()
+			return x + obj.Width/2, y + obj.Height, true
+		}
+	}
+	return 0, 0, false
+}
+
+// PlayerPosition returns the bottom center of the player's collider, where PlacePlayer places it.
+func (l *Level) PlayerPosition() (x, y float32, ok bool) {
+	c := ecs.Get[Collider](l.entities, l.player)
+	if c == nil {
+		return 0, 0, false
+	}
+
+	minX, _, maxX, maxY := c.AABB()
+	return (minX + maxX) / 2, maxY, true
+}
+
+// PlacePlayer moves the player so the bottom center of its collider is at x, y.
+func (l *Level) PlacePlayer(x, y float32) {
+	c := ecs.Get[Collider](l.entities, l.player)
+	if c == nil {
+		return
+	}
+
+	posX, posY := c.Position()
+	minX, minY, maxX, maxY := c.AABB()
+	c.SetPosition(x-(maxX-minX)/2-(minX-posX), y-(maxY-minY)-(minY-posY))
+
+	if t := ecs.Get[Transform](l.entities, l.player); t != nil {
+		t.X, t.Y = c.Position()
+	}
+	l.follow(1)
+}
+
+// PlayerState returns the state of the player to carry to the next level.
+func (l *Level) PlayerState() (PlayerState, bool) {
+	c := ecs.Get[Collider](l.entities, l.player)
+	if c == nil {
+		return PlayerState{}, false
+	}
+
+	state := PlayerState{Velocity: c.Info().Velocity}
+	if h := ecs.Get[Health](l.entities, l.player); h != nil {
+		state.Health = *h
+	}
+	return state, true
+}
+
+// RestorePlayerState applies the state carried from the previous level to the player.
+func (l *Level) RestorePlayerState(state PlayerState) {
+	if c := ecs.Get[Collider](l.entities, l.player); c != nil {
+		c.Info().SetVelocity(state.Velocity[0], state.Velocity[1])
+	}
+	if h := ecs.Get[Health](l.entities, l.player); h != nil && state.Health.Max > 0 {
+		*h = state.Health
+	}
+}
@@ -0,0 +1,120 @@
+package level
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/adm87/deepdown/scripts/physics"
+	"github.com/adm87/tiled"
+)
+
+// DecodeProperties decodes custom properties into the struct pointed to by v.
+//
+// Exported fields are matched against properties by field name, or by the name given in a `property:"Name"` tag,
+// and fields tagged `property:"-"` are skipped. Struct fields are flattened, so their own fields are matched
+// against the same properties. Fields without a matching property keep their value, which lets callers fill
+// v with defaults first. Collision layers are decoded by name and collision roles from their Tiled flags.
+func DecodeProperties(properties []tiled.Property, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("properties must decode into a struct pointer, got %T", v)
+	}
+	return decodeStruct(properties, rv.Elem())
+}
+
+func decodeStruct(properties []tiled.Property, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("property"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+
+		value := rv.Field(i)
+		if value.Kind() == reflect.Struct {
+			if err := decodeStruct(properties, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		prop := propertyByName(properties, name)
+		if prop == nil {
+			continue
+		}
+		if err := decodeProperty(prop, value); err != nil {
+			return fmt.Errorf("property %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func decodeProperty(prop *tiled.Property, value reflect.Value) error {
+	switch target := value.Addr().Interface().(type) {
+	case *physics.Layer:
+		if prop.Value == "" {
+			*target = physics.CollisionLayerDefault
+			return nil
+		}
+		layer, ok := physics.LayerByName(prop.Value)
+		if !ok {
+			return fmt.Errorf("unknown collision layer: %s", prop.Value)
+		}
+		*target = layer
+		return nil
+
+	case *physics.Role:
+		role, err := parseCollisionRole(prop.Value)
+		if err != nil {
+			return err
+		}
+		*target = role
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(prop.Value)
+
+	case reflect.Bool:
+		v, err := strconv.ParseBool(prop.Value)
+		if err != nil {
+			return err
+		}
+		value.SetBool(v)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(prop.Value, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(v)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(prop.Value, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(v)
+
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(prop.Value, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(v)
+
+	default:
+		return fmt.Errorf("unsupported field type %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package level
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/adm87/deepdown/scripts/assets"
+	"github.com/adm87/deepdown/scripts/ecs"
+	"github.com/adm87/deepdown/scripts/physics"
+)
+
+const (
+	DefaultStreamChunkSize int32 = 16 // Tiles per side of a streamed chunk
+	DefaultStreamMargin    int32 = 1  // Chunks kept loaded around the simulated area
+)
+
+// StreamConfig controls how the tile collision of a map is streamed into the physics world.
+//
+// A streamed map only keeps the tile colliders of the chunks around the camera's simulated area and around
+// bodies that are always simulated, building chunks as they come within the margin and releasing them to the
+// collider pool once they are a chunk beyond it, so large maps run at constant memory. Infinite maps are always streamed, and finite
+// maps are streamed when their "Streaming" property is set. Object colliders are built once for the whole map.
+type StreamConfig struct {
+	Enabled   bool  `property:"Streaming"`
+	ChunkSize int32 `property:"StreamChunkSize"` // Tiles per side of a chunk
+	Margin    int32 `property:"StreamMargin"`    // Chunks loaded beyond the simulated area
+}
+
+// streamConfig returns the stream config declared by the map properties of a tilemap.
+func streamConfig(tm *assets.Tilemap) (StreamConfig, error) {
+	config := StreamConfig{
+		Enabled:   tm.Infinite,
+		ChunkSize: DefaultStreamChunkSize,
+		Margin:    DefaultStreamMargin,
+	}
+	if err := DecodeProperties(tm.Properties, &config); err != nil {
+		return config, err
+	}
+	if tm.Infinite {
+		config.Enabled = true
+	}
+	if config.ChunkSize <= 0 {
+		return config, fmt.Errorf("invalid stream chunk size: %d", config.ChunkSize)
+	}
+	if config.Margin < 0 {
+		return config, fmt.Errorf("invalid stream margin: %d", config.Margin)
+	}
+	return config, nil
+}
+
+// streamChunk holds the tile colliders built for a chunk.
+type streamChunk struct {
+	colliders []physics.Collider
+}
+
+type streamState struct {
+	config StreamConfig
+	chunks map[[2]int32]*streamChunk // Loaded chunks by chunk coordinates
+	ranges []chunkRange              // Chunk ranges kept loaded, reused between steps
+}
+
+// StreamedChunks returns the number of tile chunks loaded in the physics world, or 0 if the map is not streamed.
+func (l *Level) StreamedChunks() int {
+	return len(l.stream.chunks)
+}
+
+// streamTiles loads the chunks around the camera's simulated area and always simulated bodies, and unloads the chunks beyond them.
+func (l *Level) streamTiles(w *ecs.World, dt float64) {
+	if err := l.streamChunks(); err != nil {
+		l.ctx.Logger().Error("Failed to stream tile collision", slog.String("error", err.Error()))
+	}
+}
+
+// chunkRange is an inclusive range of chunk coordinates.
+type chunkRange struct {
+	x0, y0, x1, y1 int32
+}
+
+func (r chunkRange) contains(key [2]int32, margin int32) bool {
+	return key[0] >= r.x0-margin && key[0] <= r.x1+margin && key[1] >= r.y0-margin && key[1] <= r.y1+margin
+}
+
+func (l *Level) streamChunks() error {
+	if !l.stream.config.Enabled || l.source == nil {
+		return nil
+	}
+
+	margin := l.stream.config.Margin
+
+	// Always simulated bodies move outside the camera's simulated area, so the ground under them is kept too
+	minX, minY, maxX, maxY := l.camera.Viewport()
+	simMargin := l.world.Config().SimulationMargin
+	ranges := append(l.stream.ranges[:0], l.chunkRange(minX-simMargin, minY-simMargin, maxX+simMargin, maxY+simMargin))
+	for _, body := range l.world.AlwaysSimulated() {
+		ranges = append(ranges, l.chunkRange(body.AABB()))
+	}
+	l.stream.ranges = ranges
+
+	// Chunks are released a chunk further than they are loaded, so moving along a chunk edge does not rebuild them
+	for key, chunk := range l.stream.chunks {
+		keep := false
+		for _, r := range ranges {
+			if r.contains(key, margin+1) {
+				keep = true
+				break
+			}
+		}
+		if !keep {
+			l.releaseChunk(chunk)
+			delete(l.stream.chunks, key)
+		}
+	}
+
+	for _, r := range ranges {
+		if err := l.loadChunks(r, margin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadChunks builds the chunks of a range and its margin that are not loaded yet.
+func (l *Level) loadChunks(r chunkRange, margin int32) error {
+	tm := l.source
+	size := l.stream.config.ChunkSize
+	tileMinX, tileMinY, tileMaxX, tileMaxY := tm.TileBounds()
+
+	for cy := r.y0 - margin; cy <= r.y1+margin; cy++ {
+		for cx := r.x0 - margin; cx <= r.x1+margin; cx++ {
+			key := [2]int32{cx, cy}
+			if _, ok := l.stream.chunks[key]; ok {
+				continue
+			}
+
+			originX, originY := cx*size, cy*size
+			if originX >= tileMaxX || originY >= tileMaxY || originX+size <= tileMinX || originY+size <= tileMinY {
+				continue
+			}
+
+			colliders, err := l.buildTileRegion(tm, originX, originY, size, size)
+			l.stream.chunks[key] = &streamChunk{colliders: colliders}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// chunkRange returns the range of chunks covering a world area.
+func (l *Level) chunkRange(minX, minY, maxX, maxY float32) chunkRange {
+	x0, y0 := l.chunkAt(minX, minY)
+	x1, y1 := l.chunkAt(maxX, maxY)
+	return chunkRange{x0: x0, y0: y0, x1: x1, y1: y1}
+}
+
+// chunkAt returns the coordinates of the chunk containing a world position.
+func (l *Level) chunkAt(x, y float32) (cx, cy int32) {
+	size := float64(l.stream.config.ChunkSize)
+	tileWidth, tileHeight := float64(l.source.TileWidth), float64(l.source.TileHeight)
+	cx = int32(math.Floor(float64(x) / tileWidth / size))
+	cy = int32(math.Floor(float64(y) / tileHeight / size))
+	return cx, cy
+}
+
+// releaseChunk removes the colliders of a chunk from the world and returns them to the pool.
+func (l *Level) releaseChunk(chunk *streamChunk) {
+	for _, collider := range chunk.colliders {
+		l.world.RemoveCollider(collider)
+		l.world.Pool().Release(collider)
+	}
+	chunk.colliders = nil
+}
@@ -0,0 +1,103 @@
+package level
+
+import (
+	"github.com/adm87/deepdown/scripts/ecs"
+	"github.com/adm87/deepdown/scripts/input"
+	"github.com/adm87/deepdown/scripts/input/actions"
+	"github.com/adm87/deepdown/scripts/physics"
+)
+
+// registerSystems adds the level's systems to its phases. Game code may add its own systems after them.
+func (l *Level) registerSystems() {
+	l.entities.AddSystem(ecs.PhaseUpdate, updatePlayerInput)
+	l.entities.AddSystem(ecs.PhaseUpdate, l.animateTiles)
+
+	l.entities.AddSystem(ecs.PhaseFixedUpdate, l.streamTiles)
+	l.entities.AddSystem(ecs.PhaseFixedUpdate, l.stepPhysics)
+	l.entities.AddSystem(ecs.PhaseFixedUpdate, syncTransforms)
+
+	l.entities.AddSystem(ecs.PhaseLateUpdate, destroyDead)
+	l.entities.AddSystem(ecs.PhaseLateUpdate, l.usePortals)
+	l.entities.AddSystem(ecs.PhaseLateUpdate, func(w *ecs.World, dt float64) {
+		l.follow(1)
+	})
+}
+
+// updatePlayerInput moves, climbs, jumps and drops through platforms following the player's input actions.
+func updatePlayerInput(w *ecs.World, dt float64) {
+	ecs.Each2(w, func(e ecs.Entity, _ *PlayerInput, c *Collider) {
+		info := c.Info()
+
+		if input.IsActive(actions.MoveLeft) {
+			info.AddImpulse(-actions.MovementSpeed*info.Body.Mass, 0)
+		}
+		if input.IsActive(actions.MoveRight) {
+			info.AddImpulse(actions.MovementSpeed*info.Body.Mass, 0)
+		}
+		if info.OnClimbable() {
+			updateClimbing(info)
+		}
+		if jump := input.GetBinding[*input.KeyPressDurationBinding](actions.Jump); jump != nil && !climbedUp(info) {
+			if info.OnOneWayPlatform() && input.IsActive(actions.MoveDown) && jump.JustReleased() {
+				info.DropThrough()
+			} else if canJump(info) && jump.JustReleased() {
+				pressure := jump.Pressure()
+				info.SetVelocity(info.Velocity[0], actions.JumpVelocity*float32(pressure))
+				info.OnGround = false
+				info.Climbing = false
+			}
+		}
+	})
+}
+
+// canJump returns true while grounded, within coyote time, climbing or swimming.
+func canJump(info *physics.ColliderInfo) bool {
+	return info.TimeSinceLeftGround() <= CoyoteTime || info.Climbing || info.InWater()
+}
+
+// climbedUp returns true when the up key, shared by MoveUp and Jump, was let go on a climbable,
+// so climbing up a ladder does not also jump off it.
+func climbedUp(info *physics.ColliderInfo) bool {
+	up := input.GetBinding[*input.KeyHoldBinding](actions.MoveUp)
+	return info.OnClimbable() && up != nil && up.JustReleased()
+}
+
+// updateClimbing grabs onto a climbable volume when moving up or down, and holds still on it otherwise.
+func updateClimbing(info *physics.ColliderInfo) {
+	var climbY float32
+	if input.IsActive(actions.MoveUp) {
+		climbY -= actions.ClimbSpeed
+	}
+	if input.IsActive(actions.MoveDown) {
+		climbY += actions.ClimbSpeed
+	}
+
+	if climbY != 0 {
+		info.Climbing = true
+	}
+	if info.Climbing {
+		info.SetVelocity(info.Velocity[0], climbY)
+	}
+}
+
+// stepPhysics steps the physics world, simulating bodies within the camera's viewport.
+func (l *Level) stepPhysics(w *ecs.World, dt float64) {
+	minX, minY, maxX, maxY := l.camera.Viewport()
+	l.world.Update(dt, minX, minY, maxX, maxY)
+}
+
+// syncTransforms copies the position of colliders into the transforms of their entities.
+func syncTransforms(w *ecs.World, dt float64) {
+	ecs.Each2(w, func(e ecs.Entity, t *Transform, c *Collider) {
+		t.X, t.Y = c.Position()
+	})
+}
+
+// destroyDead destroys the entities that ran out of health, leaving the player to game code.
+func destroyDead(w *ecs.World, dt float64) {
+	ecs.Each(w, func(e ecs.Entity, h *Health) {
+		if h.IsDead() && !ecs.Has[PlayerInput](w, e) {
+			w.Destroy(e)
+		}
+	})
+}
@@ -0,0 +1,399 @@
+package level
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/adm87/deepdown/scripts/assets"
+	"github.com/adm87/deepdown/scripts/geom"
+	"github.com/adm87/deepdown/scripts/physics"
+	"github.com/adm87/tiled"
+)
+
+// tileSlope identifies collision shapes that cover half a tile along its diagonal.
+// Adjacent slope tiles continuing the same diagonal are merged into a single triangle.
+type tileSlope uint8
+
+const (
+	tileSlopeNone        tileSlope = iota
+	tileSlopeFloorUp               // Solid bottom-right, rising to the right
+	tileSlopeFloorDown             // Solid bottom-left, falling to the right
+	tileSlopeCeilingDown           // Solid top-right, falling to the right
+	tileSlopeCeilingUp             // Solid top-left, rising to the right
+)
+
+// points returns the slope triangle covering a width by height area.
+func (ts tileSlope) points(width, height float32) [6]float32 {
+	switch ts {
+	case tileSlopeFloorUp:
+		return [6]float32{0, height, width, 0, width, height}
+	case tileSlopeFloorDown:
+		return [6]float32{0, 0, width, height, 0, height}
+	case tileSlopeCeilingDown:
+		return [6]float32{0, 0, width, 0, width, height}
+	case tileSlopeCeilingUp:
+		return [6]float32{0, 0, width, 0, 0, height}
+	default:
+		return [6]float32{}
+	}
+}
+
+// step returns the row offset of the next tile along the slope's diagonal.
+func (ts tileSlope) step() int32 {
+	switch ts {
+	case tileSlopeFloorUp, tileSlopeCeilingUp:
+		return -1
+	default:
+		return 1
+	}
+}
+
+type tileCellKind uint8
+
+const (
+	tileCellEmpty  tileCellKind = iota
+	tileCellSolid               // A single shape covering the whole tile
+	tileCellSlope               // A single half-tile slope
+	tileCellShapes              // Any other shapes, built as they are
+)
+
+type tileCell struct {
+	kind   tileCellKind
+	slope  tileSlope
+	key    string
+	x, y   float32 // World position of the tile's top-left corner
+	shapes []assets.TileShape
+}
+
+// BuildTileCollision builds static colliders from the collision shapes of tiles in every tile layer.
+// Whole-tile shapes are merged into as few rectangles as possible and half-tile slopes into triangles.
+// Layers with a "Collision" property set to false are skipped.
+func (l *Level) BuildTileCollision(tm *assets.Tilemap) error {
+	minX, minY, maxX, maxY := tm.TileBounds()
+	_, err := l.buildTileRegion(tm, minX, minY, maxX-minX, maxY-minY)
+	return err
+}
+
+// buildTileRegion builds the tile colliders of a region of every tile layer, given in tile coordinates,
+// and returns the colliders added to the world. Tiles are only merged with tiles in the same region.
+func (l *Level) buildTileRegion(tm *assets.Tilemap, originX, originY, width, height int32) ([]physics.Collider, error) {
+	var colliders []physics.Collider
+
+	for i := range tm.TileLayers {
+		layer := &tm.TileLayers[i]
+		if layer.Properties["Collision"] == "false" {
+			continue
+		}
+
+		cells := l.tileCells(tm, layer, originX, originY, width, height)
+		used := make([]bool, len(cells))
+
+		tileWidth, tileHeight := float32(tm.TileWidth), float32(tm.TileHeight)
+
+		for y := range height {
+			for x := range width {
+				idx := y*width + x
+				if used[idx] {
+					continue
+				}
+
+				cell := &cells[idx]
+				switch cell.kind {
+				case tileCellSolid:
+					w, h := mergeSolidTiles(cells, used, width, height, x, y)
+					collider := l.world.Pool().GetBoxCollider(cell.x, cell.y, float32(w)*tileWidth, float32(h)*tileHeight)
+					if err := l.addTileCollider(collider, cell.shapes[0].Properties); err != nil {
+						return colliders, err
+					}
+					colliders = append(colliders, collider)
+
+				case tileCellSlope:
+					startX, startY, length := mergeSlopeTiles(cells, used, width, height, x, y)
+					start := &cells[startY*width+startX]
+					slopeY := start.y
+					if cell.slope.step() < 0 {
+						slopeY -= float32(length-1) * tileHeight
+					}
+					points := cell.slope.points(float32(length)*tileWidth, float32(length)*tileHeight)
+					collider := l.world.Pool().GetTriangleCollider(start.x, slopeY, points)
+					if err := l.addTileCollider(collider, cell.shapes[0].Properties); err != nil {
+						return colliders, err
+					}
+					colliders = append(colliders, collider)
+
+				case tileCellShapes:
+					used[idx] = true
+					for j := range cell.shapes {
+						added, err := l.addTileShape(cell.x, cell.y, &cell.shapes[j])
+						colliders = append(colliders, added...)
+						if err != nil {
+							return colliders, err
+						}
+					}
+				}
+			}
+		}
+	}
+	return colliders, nil
+}
+
+// tileCells resolves and classifies the collision shapes of every tile in a region of a layer.
+func (l *Level) tileCells(tm *assets.Tilemap, layer *assets.TileLayer, originX, originY, width, height int32) []tileCell {
+	cells := make([]tileCell, width*height)
+
+	for y := range height {
+		for x := range width {
+			gid := layer.GID(originX+x, originY+y)
+			if gid == 0 {
+				continue
+			}
+
+			ts, ok := tm.TilesetByGID(gid)
+			if !ok {
+				continue
+			}
+
+			tileset, ok := assets.Get[*assets.Tileset](ts.Source)
+			if !ok {
+				l.ctx.Logger().Warn("Tileset not loaded for tile collision", slog.String("tileset", ts.Source.String()))
+				continue
+			}
+
+			tile, ok := tileset.Tiles[(gid&^assets.GIDFlipMask)-ts.FirstGID]
+			if !ok || len(tile.Collision) == 0 {
+				continue
+			}
+
+			tsWidth, tsHeight := float32(tileset.TileWidth), float32(tileset.TileHeight)
+
+			cell := &cells[y*width+x]
+			cell.kind = tileCellShapes
+			cell.x = float32((originX + x) * tm.TileWidth)
+			cell.y = float32((originY+y)*tm.TileHeight) + float32(tm.TileHeight) - tsHeight // Align to bottom of tile
+			cell.shapes = make([]assets.TileShape, len(tile.Collision))
+			for i := range tile.Collision {
+				cell.shapes[i] = flipTileShape(tile.Collision[i], gid, tsWidth, tsHeight)
+			}
+
+			// Only tiles matching the map grid can be merged
+			if len(cell.shapes) != 1 || tsWidth != float32(tm.TileWidth) || tsHeight != float32(tm.TileHeight) {
+				continue
+			}
+
+			shape := &cell.shapes[0]
+			cell.key = propertiesKey(shape.Properties)
+
+			switch {
+			case len(shape.Points) == 0 && shape.X == 0 && shape.Y == 0 && shape.Width == tsWidth && shape.Height == tsHeight:
+				cell.kind = tileCellSolid
+			case len(shape.Points) == 3:
+				if slope := classifyTileSlope(shape, tsWidth, tsHeight); slope != tileSlopeNone {
+					cell.kind = tileCellSlope
+					cell.slope = slope
+				}
+			}
+		}
+	}
+
+	return cells
+}
+
+// mergeSolidTiles greedily grows a rectangle of matching solid tiles from (x, y),
+// first along the row and then down while every tile in the next row matches.
+func mergeSolidTiles(cells []tileCell, used []bool, layerWidth, layerHeight, x, y int32) (width, height int32) {
+	key := cells[y*layerWidth+x].key
+
+	matches := func(cx, cy int32) bool {
+		idx := cy*layerWidth + cx
+		return !used[idx] && cells[idx].kind == tileCellSolid && cells[idx].key == key
+	}
+
+	for width = 1; x+width < layerWidth && matches(x+width, y); width++ {
+	}
+
+	for height = 1; y+height < layerHeight; height++ {
+		row := true
+		for cx := x; cx < x+width && row; cx++ {
+			row = matches(cx, y+height)
+		}
+		if !row {
+			break
+		}
+	}
+
+	for cy := y; cy < y+height; cy++ {
+		for cx := x; cx < x+width; cx++ {
+			used[cy*layerWidth+cx] = true
+		}
+	}
+
+	return width, height
+}
+
+// mergeSlopeTiles finds the run of matching slope tiles along the diagonal through (x, y).
+// It returns the left-most tile of the run and the number of tiles in it.
+func mergeSlopeTiles(cells []tileCell, used []bool, layerWidth, layerHeight, x, y int32) (startX, startY, length int32) {
+	cell := &cells[y*layerWidth+x]
+	step := cell.slope.step()
+
+	matches := func(cx, cy int32) bool {
+		if cx < 0 || cy < 0 || cx >= layerWidth || cy >= layerHeight {
+			return false
+		}
+		idx := cy*layerWidth + cx
+		return !used[idx] && cells[idx].kind == tileCellSlope && cells[idx].slope == cell.slope && cells[idx].key == cell.key
+	}
+
+	startX, startY = x, y
+	for matches(startX-1, startY-step) {
+		startX, startY = startX-1, startY-step
+	}
+
+	for length = 0; matches(startX+length, startY+length*step); length++ {
+		used[(startY+length*step)*layerWidth+startX+length] = true
+	}
+
+	return startX, startY, length
+}
+
+func classifyTileSlope(shape *assets.TileShape, width, height float32) tileSlope {
+	for _, slope := range []tileSlope{tileSlopeFloorUp, tileSlopeFloorDown, tileSlopeCeilingDown, tileSlopeCeilingUp} {
+		expected := slope.points(width, height)
+
+		matched := 0
+		for _, p := range shape.Points {
+			for i := 0; i < 6; i += 2 {
+				if geom.Abs(shape.X+p[0]-expected[i]) < geom.Epsilon && geom.Abs(shape.Y+p[1]-expected[i+1]) < geom.Epsilon {
+					matched++
+					break
+				}
+			}
+		}
+
+		if matched == 3 {
+			return slope
+		}
+	}
+	return tileSlopeNone
+}
+
+// flipTileShape applies the flip flags of a GID to a tile collision shape.
+func flipTileShape(shape assets.TileShape, gid uint32, width, height float32) assets.TileShape {
+	if gid&assets.GIDFlipMask == 0 {
+		return shape
+	}
+
+	flip := func(x, y float32) (float32, float32) {
+		w, h := width, height
+		if gid&assets.GIDFlipDiagonal != 0 {
+			x, y = y, x
+			w, h = h, w
+		}
+		if gid&assets.GIDFlipHorizontal != 0 {
+			x = w - x
+		}
+		if gid&assets.GIDFlipVertical != 0 {
+			y = h - y
+		}
+		return x, y
+	}
+
+	result := assets.TileShape{Properties: shape.Properties}
+
+	if len(shape.Points) == 0 {
+		x1, y1 := flip(shape.X, shape.Y)
+		x2, y2 := flip(shape.X+shape.Width, shape.Y+shape.Height)
+		result.X, result.Y = min(x1, x2), min(y1, y2)
+		result.Width, result.Height = geom.Abs(x2-x1), geom.Abs(y2-y1)
+		return result
+	}
+
+	result.Points = make([][2]float32, len(shape.Points))
+	for i, p := range shape.Points {
+		x, y := flip(shape.X+p[0], shape.Y+p[1])
+		result.Points[i] = [2]float32{x, y}
+	}
+	return result
+}
+
+// addTileShape builds a single tile collision shape positioned at the tile's top-left corner.
+// It returns the colliders added to the world.
+func (l *Level) addTileShape(x, y float32, shape *assets.TileShape) ([]physics.Collider, error) {
+	x += shape.X
+	y += shape.Y
+
+	if len(shape.Points) == 0 {
+		collider := l.world.Pool().GetBoxCollider(x, y, shape.Width, shape.Height)
+		if err := l.addTileCollider(collider, shape.Properties); err != nil {
+			return nil, err
+		}
+		return []physics.Collider{collider}, nil
+	}
+
+	// Concave shapes are split into convex pieces
+	pieces := geom.DecomposePolygon(shape.Points)
+	if len(pieces) == 0 {
+		l.ctx.Logger().Warn("Skipping degenerate tile collision shape")
+		return nil, nil
+	}
+
+	colliders := make([]physics.Collider, 0, len(pieces))
+	for _, piece := range pieces {
+		collider := l.convexCollider(x, y, piece)
+		if err := l.addTileCollider(collider, shape.Properties); err != nil {
+			return colliders, err
+		}
+		colliders = append(colliders, collider)
+	}
+	return colliders, nil
+}
+
+func (l *Level) addTileCollider(collider physics.Collider, properties map[string]string) error {
+	props := tileProperties(properties)
+
+	var role physics.Role
+	if prop := propertyByName(props, "CollisionRole"); prop != nil {
+		r, err := parseCollisionRole(prop.Value)
+		if err != nil {
+			return err
+		}
+		role = r
+	}
+
+	material, err := physicsMaterial(props)
+	if err != nil {
+		return err
+	}
+
+	layer, err := collisionLayer(props)
+	if err != nil {
+		return err
+	}
+
+	collider.Info().Role = role
+	collider.Info().Layer = layer
+	collider.Info().State = physics.ColliderStateStatic
+	collider.Info().Material = material
+
+	l.world.AddCollider(collider)
+	return nil
+}
+
+func tileProperties(properties map[string]string) []tiled.Property {
+	result := make([]tiled.Property, 0, len(properties))
+	for name, value := range properties {
+		result = append(result, tiled.Property{Name: name, Value: value})
+	}
+	return result
+}
+
+// propertiesKey returns a string identifying a set of properties, used to only merge matching tiles.
+func propertiesKey(properties map[string]string) string {
+	keys := make([]string, 0, len(properties))
+	for name, value := range properties {
+		keys = append(keys, name+"="+value)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ";")
+}
@@ -0,0 +1,337 @@
+package physics
+
+const aabbTreeNull int32 = -1
+
+type aabbNode struct {
+	minX, minY, maxX, maxY float32
+
+	collider Collider // Set for leaves only
+	parent   int32
+	left     int32
+	right    int32
+	height   int32 // 0 for leaves, -1 for free nodes
+}
+
+func (n *aabbNode) isLeaf() bool {
+	return n.left == aabbTreeNull
+}
+
+// AABBTree is a dynamic bounding volume hierarchy balanced with tree rotations.
+// Leaves store bounds enlarged by a margin so small movements do not restructure the tree,
+// which suits levels with large colliders and many moving bodies.
+type AABBTree struct {
+	margin float32
+
+	nodes  []aabbNode
+	free   []int32
+	root   int32
+	leaves map[Collider]int32
+
+	stack   []int32    // Reusable traversal stack
+	results []Collider // Reusable query results
+}
+
+func NewAABBTree(margin float32) *AABBTree {
+	return &AABBTree{
+		margin: margin,
+		root:   aabbTreeNull,
+		leaves: make(map[Collider]int32),
+	}
+}
+
+func (t *AABBTree) Insert(collider Collider) {
+	if _, exists := t.leaves[collider]; exists {
+		return
+	}
+
+	leaf := t.allocate()
+	node := &t.nodes[leaf]
+	node.collider = collider
+	node.height = 0
+	t.fatten(leaf)
+
+	t.leaves[collider] = leaf
+	t.insertLeaf(leaf)
+}
+
+func (t *AABBTree) Remove(collider Collider) {
+	leaf, exists := t.leaves[collider]
+	if !exists {
+		return
+	}
+
+	delete(t.leaves, collider)
+	t.removeLeaf(leaf)
+	t.release(leaf)
+}
+
+func (t *AABBTree) Update(collider Collider) {
+	leaf, exists := t.leaves[collider]
+	if !exists {
+		t.Insert(collider)
+		return
+	}
+
+	// Nothing to do while the collider stays within its enlarged bounds
+	node := &t.nodes[leaf]
+	minX, minY, maxX, maxY := collider.AABB()
+	if minX >= node.minX && minY >= node.minY && maxX <= node.maxX && maxY <= node.maxY {
+		return
+	}
+
+	t.removeLeaf(leaf)
+	t.fatten(leaf)
+	t.insertLeaf(leaf)
+}
+
+// Query returns the colliders overlapping the bounds.
+// The returned slice is reused between queries.
+func (t *AABBTree) Query(minX, minY, maxX, maxY float32) []Collider {
+	t.results = t.results[:0]
+	if t.root == aabbTreeNull {
+		return t.results
+	}
+
+	t.stack = append(t.stack[:0], t.root)
+	for len(t.stack) > 0 {
+		index := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+
+		node := &t.nodes[index]
+		if !overlapsAABB(node.minX, node.minY, node.maxX, node.maxY, minX, minY, maxX, maxY) {
+			continue
+		}
+
+		if node.isLeaf() {
+			// Confirm against the tight bounds to skip colliders only inside the margin
+			cMinX, cMinY, cMaxX, cMaxY := node.collider.AABB()
+			if overlapsAABB(minX, minY, maxX, maxY, cMinX, cMinY, cMaxX, cMaxY) {
+				t.results = append(t.results, node.collider)
+			}
+			continue
+		}
+
+		t.stack = append(t.stack, node.left, node.right)
+	}
+
+	return t.results
+}
+
+func (t *AABBTree) allocate() int32 {
+	if n := len(t.free); n > 0 {
+		index := t.free[n-1]
+		t.free = t.free[:n-1]
+		t.nodes[index] = aabbNode{parent: aabbTreeNull, left: aabbTreeNull, right: aabbTreeNull}
+		return index
+	}
+
+	t.nodes = append(t.nodes, aabbNode{parent: aabbTreeNull, left: aabbTreeNull, right: aabbTreeNull})
+	return int32(len(t.nodes) - 1)
+}
+
+func (t *AABBTree) release(index int32) {
+	t.nodes[index] = aabbNode{height: -1}
+	t.free = append(t.free, index)
+}
+
+func (t *AABBTree) fatten(leaf int32) {
+	node := &t.nodes[leaf]
+	minX, minY, maxX, maxY := node.collider.AABB()
+	node.minX, node.minY = minX-t.margin, minY-t.margin
+	node.maxX, node.maxY = maxX+t.margin, maxY+t.margin
+}
+
+func (t *AABBTree) insertLeaf(leaf int32) {
+	if t.root == aabbTreeNull {
+		t.root = leaf
+		t.nodes[leaf].parent = aabbTreeNull
+		return
+	}
+
+	// Find the sibling that grows the tree's total perimeter the least
+	l := t.nodes[leaf]
+	index := t.root
+	for !t.nodes[index].isLeaf() {
+		node := &t.nodes[index]
+
+		area := perimeter(node.minX, node.minY, node.maxX, node.maxY)
+		combined := perimeter(union(node.minX, node.minY, node.maxX, node.maxY, l.minX, l.minY, l.maxX, l.maxY))
+
+		// Cost of pairing the leaf with this node, and of pushing it further down
+		cost := 2 * combined
+		inheritance := 2 * (combined - area)
+
+		leftCost := t.descendCost(node.left, &l) + inheritance
+		rightCost := t.descendCost(node.right, &l) + inheritance
+
+		if cost < leftCost && cost < rightCost {
+			break
+		}
+		if leftCost < rightCost {
+			index = node.left
+		} else {
+			index = node.right
+		}
+	}
+
+	sibling := index
+	oldParent := t.nodes[sibling].parent
+
+	newParent := t.allocate()
+	s := &t.nodes[sibling]
+	p := &t.nodes[newParent]
+	p.parent = oldParent
+	p.minX, p.minY, p.maxX, p.maxY = union(s.minX, s.minY, s.maxX, s.maxY, l.minX, l.minY, l.maxX, l.maxY)
+	p.height = s.height + 1
+	p.left = sibling
+	p.right = leaf
+
+	if oldParent != aabbTreeNull {
+		if t.nodes[oldParent].left == sibling {
+			t.nodes[oldParent].left = newParent
+		} else {
+			t.nodes[oldParent].right = newParent
+		}
+	} else {
+		t.root = newParent
+	}
+	t.nodes[sibling].parent = newParent
+	t.nodes[leaf].parent = newParent
+
+	t.refit(t.nodes[leaf].parent)
+}
+
+func (t *AABBTree) removeLeaf(leaf int32) {
+	if leaf == t.root {
+		t.root = aabbTreeNull
+		return
+	}
+
+	parent := t.nodes[leaf].parent
+	grandParent := t.nodes[parent].parent
+
+	sibling := t.nodes[parent].left
+	if sibling == leaf {
+		sibling = t.nodes[parent].right
+	}
+
+	if grandParent != aabbTreeNull {
+		if t.nodes[grandParent].left == parent {
+			t.nodes[grandParent].left = sibling
+		} else {
+			t.nodes[grandParent].right = sibling
+		}
+		t.nodes[sibling].parent = grandParent
+		t.release(parent)
+		t.refit(grandParent)
+	} else {
+		t.root = sibling
+		t.nodes[sibling].parent = aabbTreeNull
+		t.release(parent)
+	}
+
+	t.nodes[leaf].parent = aabbTreeNull
+}
+
+// descendCost returns the perimeter increase of inserting a leaf below the given child.
+func (t *AABBTree) descendCost(child int32, leaf *aabbNode) float32 {
+	c := &t.nodes[child]
+	combined := perimeter(union(c.minX, c.minY, c.maxX, c.maxY, leaf.minX, leaf.minY, leaf.maxX, leaf.maxY))
+	if c.isLeaf() {
+		return combined
+	}
+	return combined - perimeter(c.minX, c.minY, c.maxX, c.maxY)
+}
+
+// refit walks from a node to the root rebalancing and recomputing bounds and heights.
+func (t *AABBTree) refit(index int32) {
+	for index != aabbTreeNull {
+		index = t.balance(index)
+
+		node := &t.nodes[index]
+		left, right := &t.nodes[node.left], &t.nodes[node.right]
+		node.height = 1 + max(left.height, right.height)
+		node.minX, node.minY, node.maxX, node.maxY = union(left.minX, left.minY, left.maxX, left.maxY, right.minX, right.minY, right.maxX, right.maxY)
+
+		index = node.parent
+	}
+}
+
+// balance rotates the subtree at a if its children's heights differ by more than one.
+// It returns the index of the subtree's new root.
+func (t *AABBTree) balance(a int32) int32 {
+	A := &t.nodes[a]
+	if A.isLeaf() || A.height < 2 {
+		return a
+	}
+
+	b, c := A.left, A.right
+	B, C := &t.nodes[b], &t.nodes[c]
+
+	switch diff := C.height - B.height; {
+	case diff > 1:
+		// Rotate C up
+		t.rotate(a, c, false)
+		return c
+	case diff < -1:
+		// Rotate B up
+		t.rotate(a, b, true)
+		return b
+	default:
+		return a
+	}
+}
+
+// rotate promotes child up in place of a, moving a down to become one of child's children.
+// fromLeft tells whether child was a's left child.
+func (t *AABBTree) rotate(a, child int32, fromLeft bool) {
+	A := &t.nodes[a]
+	C := &t.nodes[child]
+	f, g := C.left, C.right
+	F, G := &t.nodes[f], &t.nodes[g]
+
+	// Swap a and child
+	C.left = a
+	C.parent = A.parent
+	A.parent = child
+
+	if C.parent != aabbTreeNull {
+		if t.nodes[C.parent].left == a {
+			t.nodes[C.parent].left = child
+		} else {
+			t.nodes[C.parent].right = child
+		}
+	} else {
+		t.root = child
+	}
+
+	// The taller grandchild stays under child, the shorter one moves under a
+	keep, move := f, g
+	if F.height < G.height {
+		keep, move = g, f
+	}
+
+	C.right = keep
+	if fromLeft {
+		A.left = move
+	} else {
+		A.right = move
+	}
+	t.nodes[move].parent = a
+
+	left, right := &t.nodes[A.left], &t.nodes[A.right]
+	A.minX, A.minY, A.maxX, A.maxY = union(left.minX, left.minY, left.maxX, left.maxY, right.minX, right.minY, right.maxX, right.maxY)
+	A.height = 1 + max(left.height, right.height)
+
+	K := &t.nodes[keep]
+	C.minX, C.minY, C.maxX, C.maxY = union(A.minX, A.minY, A.maxX, A.maxY, K.minX, K.minY, K.maxX, K.maxY)
+	C.height = 1 + max(A.height, K.height)
+}
+
+func union(aMinX, aMinY, aMaxX, aMaxY, bMinX, bMinY, bMaxX, bMaxY float32) (minX, minY, maxX, maxY float32) {
+	return min(aMinX, bMinX), min(aMinY, bMinY), max(aMaxX, bMaxX), max(aMaxY, bMaxY)
+}
+
+func perimeter(minX, minY, maxX, maxY float32) float32 {
+	return 2 * ((maxX - minX) + (maxY - minY))
+}
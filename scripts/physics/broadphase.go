@@ -0,0 +1,109 @@
+package physics
+
+import "github.com/adm87/utilities/hash"
+
+// Broadphase finds the colliders whose bounds may overlap a region.
+// Results can contain colliders that do not overlap and must be confirmed with CheckOverlap.
+type Broadphase interface {
+	Insert(collider Collider)                        // Adds a collider using its current bounds
+	Remove(collider Collider)                        // Removes a collider
+	Update(collider Collider)                        // Refreshes a collider after its bounds changed
+	Query(minX, minY, maxX, maxY float32) []Collider // Colliders that may overlap the bounds
+}
+
+// =========== Broadphase Type ==========
+
+type BroadphaseType uint8
+
+const (
+	BroadphaseGrid BroadphaseType = iota
+	BroadphaseAABBTree
+	BroadphaseSweepAndPrune
+)
+
+func (bt BroadphaseType) String() string {
+	switch bt {
+	case BroadphaseGrid:
+		return "Grid"
+	case BroadphaseAABBTree:
+		return "AABBTree"
+	case BroadphaseSweepAndPrune:
+		return "SweepAndPrune"
+	default:
+		return "Unknown"
+	}
+}
+
+func (bt BroadphaseType) IsValid() bool {
+	return bt <= BroadphaseSweepAndPrune
+}
+
+// BroadphaseTypeByName returns the broadphase type matching the given name.
+func BroadphaseTypeByName(name string) (BroadphaseType, bool) {
+	for bt := BroadphaseGrid; bt.IsValid(); bt++ {
+		if bt.String() == name {
+			return bt, true
+		}
+	}
+	return BroadphaseGrid, false
+}
+
+// NewBroadphase creates the broadphase selected by the world config.
+func NewBroadphase(config Config) Broadphase {
+	switch config.Broadphase {
+	case BroadphaseAABBTree:
+		return NewAABBTree(config.TreeMargin)
+	case BroadphaseSweepAndPrune:
+		return NewSweepAndPrune()
+	default:
+		return NewGridBroadphase(config.GridCellSize)
+	}
+}
+
+// =========== Grid ==========
+
+// GridBroadphase stores colliders in a uniform spatial hash grid.
+// Large colliders are stored in every cell they span.
+type GridBroadphase struct {
+	grid *hash.Grid[Collider]
+}
+
+func NewGridBroadphase(cellSize float32) *GridBroadphase {
+	return &GridBroadphase{
+		grid: hash.NewGrid[Collider](cellSize, cellSize),
+	}
+}
+
+func (gb *GridBroadphase) Insert(collider Collider) {
+	minX, minY, maxX, maxY := collider.AABB()
+	switch c := collider.(type) {
+	// case *TriangleCollider:
+	// 	gb.grid.InsertFunc(c, minX, minY, maxX, maxY, hash.NoGridPadding, func(cMinX, cMinY, cMaxX, cMaxY float32) bool {
+	// 		return c.Triangle.IntersectsAABB(cMinX, cMinY, cMaxX, cMaxY)
+	// 	})
+	default:
+		gb.grid.Insert(c, minX, minY, maxX, maxY, hash.NoGridPadding)
+	}
+}
+
+func (gb *GridBroadphase) Remove(collider Collider) {
+	gb.grid.Remove(collider)
+}
+
+func (gb *GridBroadphase) Update(collider Collider) {
+	gb.grid.Remove(collider)
+	gb.Insert(collider)
+}
+
+func (gb *GridBroadphase) Query(minX, minY, maxX, maxY float32) []Collider {
+	return gb.grid.Query(minX, minY, maxX, maxY)
+}
+
+// QueryCells returns the keys of the occupied cells within the bounds.
+func (gb *GridBroadphase) QueryCells(minX, minY, maxX, maxY float32) []uint64 {
+	return gb.grid.QueryCells(minX, minY, maxX, maxY)
+}
+
+func overlapsAABB(aMinX, aMinY, aMaxX, aMaxY, bMinX, bMinY, bMaxX, bMaxY float32) bool {
+	return aMinX <= bMaxX && aMaxX >= bMinX && aMinY <= bMaxY && aMaxY >= bMinY
+}
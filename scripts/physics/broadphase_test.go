@@ -0,0 +1,100 @@
+package physics
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestBroadphaseMatchesBruteForce(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func() Broadphase
+	}{
+		{name: "AABBTree", new: func() Broadphase { return NewAABBTree(DefaultConfig().TreeMargin) }},
+		{name: "AABBTree without margin", new: func() Broadphase { return NewAABBTree(0) }},
+		{name: "SweepAndPrune", new: func() Broadphase { return NewSweepAndPrune() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewPCG(1, 2))
+			pool := NewColliderPool(false)
+			bp := tt.new()
+
+			var colliders []*BoxCollider
+			for range 300 {
+				c := pool.GetBoxCollider(rng.Float32()*1000-500, rng.Float32()*1000-500, 1+rng.Float32()*40, 1+rng.Float32()*40)
+				colliders = append(colliders, c)
+				bp.Insert(c)
+			}
+			checkQueries(t, "after insert", rng, bp, colliders)
+
+			// Move half the colliders, some far and some within the tree margin
+			for _, c := range colliders[:150] {
+				x, y := c.Position()
+				if rng.IntN(2) == 0 {
+					c.SetPosition(x+rng.Float32()*2-1, y+rng.Float32()*2-1)
+				} else {
+					c.SetPosition(rng.Float32()*1000-500, rng.Float32()*1000-500)
+				}
+				bp.Update(c)
+			}
+			checkQueries(t, "after update", rng, bp, colliders)
+
+			for _, c := range colliders[200:] {
+				bp.Remove(c)
+			}
+			colliders = colliders[:200]
+			checkQueries(t, "after remove", rng, bp, colliders)
+
+			for _, c := range colliders {
+				bp.Remove(c)
+			}
+			if got := bp.Query(-1000, -1000, 1000, 1000); len(got) != 0 {
+				t.Errorf("empty broadphase returned %d colliders", len(got))
+			}
+		})
+	}
+}
+
+// checkQueries compares random region queries, including points and regions covering everything,
+// against a brute force scan of the stored colliders.
+func checkQueries(t *testing.T, stage string, rng *rand.Rand, bp Broadphase, colliders []*BoxCollider) {
+	t.Helper()
+
+	regions := [][4]float32{
+		{-1000, -1000, 1000, 1000},
+		{0, 0, 0, 0},
+	}
+	for range 200 {
+		x, y := rng.Float32()*1000-500, rng.Float32()*1000-500
+		regions = append(regions, [4]float32{x, y, x + rng.Float32()*200, y + rng.Float32()*200})
+	}
+
+	for _, r := range regions {
+		want := make(map[Collider]bool)
+		for _, c := range colliders {
+			minX, minY, maxX, maxY := c.AABB()
+			if overlapsAABB(minX, minY, maxX, maxY, r[0], r[1], r[2], r[3]) {
+				want[c] = true
+			}
+		}
+
+		got := make(map[Collider]bool)
+		for _, c := range bp.Query(r[0], r[1], r[2], r[3]) {
+			if got[c] {
+				t.Fatalf("%s: query %v returned a collider twice", stage, r)
+			}
+			got[c] = true
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("%s: query %v returned %d colliders, want %d", stage, r, len(got), len(want))
+		}
+		for c := range want {
+			if !got[c] {
+				t.Fatalf("%s: query %v missed a collider", stage, r)
+			}
+		}
+	}
+}
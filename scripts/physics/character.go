@@ -0,0 +1,371 @@
+package physics
+
+import (
+	"math"
+	"slices"
+
+	"github.com/adm87/deepdown/scripts/geom"
+)
+
+// CharacterConfig holds the movement parameters of a CharacterController.
+type CharacterConfig struct {
+	MaxSlopeAngle float32 // Steepest walkable slope in degrees, 0 uses the world's MaxSlopeAngle
+	StepHeight    float32 // Tallest ledge the character steps onto while walking
+	SnapDistance  float32 // Farthest the character is pulled down to stay on descending slopes
+	MaxIterations int32   // Maximum overlaps resolved per axis of movement
+}
+
+// DefaultCharacterConfig returns the character parameters used when an object does not override them.
+func DefaultCharacterConfig() CharacterConfig {
+	return CharacterConfig{
+		StepHeight:    4.0,
+		SnapDistance:  4.0,
+		MaxIterations: 4,
+	}
+}
+
+// CharacterContacts describes the surfaces a character touched during its last move.
+// Normals point away from the touched surface, towards the character.
+type CharacterContacts struct {
+	Ground        bool
+	GroundNormal  [2]float32
+	Ceiling       bool
+	CeilingNormal [2]float32
+	Wall          bool
+	WallNormal    [2]float32
+
+	ground Collider
+}
+
+// CharacterController moves a collider with move-and-slide instead of letting the world simulate it.
+// Walkable slopes are climbed without sliding back down, small ledges are stepped onto,
+// and the character is snapped to the ground when walking down slopes.
+// Gravity, forces and friction are applied the same way as for dynamic bodies.
+type CharacterController struct {
+	Config CharacterConfig
+
+	world    *World
+	collider Collider
+	contacts CharacterContacts
+
+	startBottom float32 // Bottom of the collider before the current move
+	moveY       float32 // Vertical direction of the current move
+}
+
+func NewCharacterController(collider Collider, config CharacterConfig) *CharacterController {
+	return &CharacterController{
+		Config:   config,
+		collider: collider,
+	}
+}
+
+// Collider returns the collider moved by the controller.
+func (cc *CharacterController) Collider() Collider {
+	return cc.collider
+}
+
+// Contacts returns the surfaces touched during the last move.
+func (cc *CharacterController) Contacts() CharacterContacts {
+	return cc.contacts
+}
+
+// AddCharacter adds a character controller and its collider to the world.
+// Characters are moved every fixed step regardless of the update region.
+func (w *World) AddCharacter(cc *CharacterController) {
+	cc.world = w
+	cc.collider.Info().State = ColliderStateCharacter
+	w.AddCollider(cc.collider)
+	w.characters = append(w.characters, cc)
+}
+
+// RemoveCharacter removes a character controller and its collider from the world.
+func (w *World) RemoveCharacter(cc *CharacterController) {
+	w.RemoveCollider(cc.collider)
+	w.characters = slices.DeleteFunc(w.characters, func(other *CharacterController) bool {
+		return other == cc
+	})
+	cc.world = nil
+}
+
+func (w *World) updateCharacters(dt float64) {
+	for _, cc := range w.characters {
+		cc.update(float32(dt))
+	}
+}
+
+// update integrates the character's velocity for a fixed step and moves it.
+func (cc *CharacterController) update(dt float32) {
+	cfg := &cc.world.config
+	info := cc.collider.Info()
+
+	info.integrateForces(dt)
+	cc.world.updateVolumes(cc.collider, info)
+
+	if info.dropThroughTime > 0 {
+		info.dropThroughTime = max(info.dropThroughTime-dt, 0)
+	}
+
+	// Moving upward leaves the ground, otherwise gravity only applies while airborne.
+	// Buoyancy can lift the character off the ground.
+	riseSpeed, fallSpeed := info.Body.speedLimits(cfg)
+	velY := clamp(info.Velocity[1]+cc.world.verticalAcceleration(info)*dt, riseSpeed, fallSpeed)
+	if info.OnGround && info.Velocity[1] >= 0 && velY >= 0 {
+		info.Velocity[1] = 0
+	} else {
+		info.Velocity[1] = velY
+	}
+	cc.world.applyWaterDrag(info)
+
+	contacts := cc.Move(info.Velocity[0]*dt, info.Velocity[1]*dt)
+
+	// Remove velocity into the touched surfaces
+	if contacts.Ground && info.Velocity[1] > 0 {
+		info.Velocity[1] = 0
+	}
+	if contacts.Ceiling && info.Velocity[1] < 0 {
+		info.Velocity[1] = 0
+	}
+	if contacts.Wall && info.Velocity[0]*contacts.WallNormal[0] < 0 {
+		info.Velocity[0] = 0
+	}
+
+	// Apply ground friction, or horizontal damping while airborne
+	if contacts.ground != nil {
+		info.Velocity[0] *= 1 - combineFriction(info.Material, contacts.ground.Info().Material)
+	} else {
+		info.Velocity[0] *= info.Body.damping(cfg)
+	}
+
+	if math.Abs(float64(info.Velocity[0])) < MinimumVelocityThreshold {
+		info.Velocity[0] = 0
+	}
+
+	if info.OnGround {
+		info.timeSinceLeftGround = 0
+	} else {
+		info.timeSinceLeftGround += dt
+	}
+}
+
+// Move moves the character by the given distance, sliding along any surfaces in the way.
+// The character must have been added to a world.
+func (cc *CharacterController) Move(dx, dy float32) CharacterContacts {
+	info := cc.collider.Info()
+	wasGrounded := info.OnGround
+
+	lastNormal := cc.contacts.GroundNormal
+
+	x, y := cc.collider.Position()
+	cc.world.savePrevious(info, x, y)
+	_, _, _, cc.startBottom = cc.collider.AABB()
+	cc.moveY = dy
+	cc.contacts = CharacterContacts{}
+
+	// Move horizontally then vertically so walls and floors resolve independently
+	if dx != 0 {
+		cc.moveAxis(dx, 0)
+		if cc.contacts.Wall && wasGrounded {
+			cc.stepUp(dx)
+		}
+	}
+	if dy != 0 {
+		cc.moveAxis(0, dy)
+	}
+
+	// Keep grounded characters on the ground while resting or walking down a slope
+	if !cc.contacts.Ground && wasGrounded && dy >= 0 {
+		cc.snapToGround(dx, lastNormal)
+	}
+
+	nX, nY := cc.collider.Position()
+	info.nextPosition = [2]float32{nX, nY}
+
+	info.OnGround = cc.contacts.Ground
+	info.ground = cc.contacts.ground
+	info.onOneWay = cc.contacts.ground != nil && cc.contacts.ground.Info().IsOneWay()
+	if info.OnGround {
+		info.restSteps = 0
+	}
+
+	cc.world.bodyPhase.Update(cc.collider)
+
+	return cc.contacts
+}
+
+// moveAxis moves the character and resolves the overlaps it moved into.
+func (cc *CharacterController) moveAxis(dx, dy float32) {
+	x, y := cc.collider.Position()
+	cc.collider.SetPosition(x+dx, y+dy)
+
+	for range cc.Config.MaxIterations {
+		contact, ok := cc.deepestContact()
+		if !ok {
+			break
+		}
+		if cc.world.collectStats {
+			cc.world.step.ResolutionIterations++
+		}
+		if dy != 0 && math.Abs(float64(contact.Normal[1])) < float64(Epsilon) {
+			contact = cc.verticalContact(contact, dy)
+		}
+		cc.resolve(&contact)
+	}
+}
+
+// verticalContact converts a sideways contact found while moving vertically into one along the movement.
+// Moving down onto the corner of a ledge would otherwise push the character off its side.
+func (cc *CharacterController) verticalContact(contact Collision, dy float32) Collision {
+	_, minY, _, maxY := cc.collider.AABB()
+	_, otherMinY, _, otherMaxY := contact.other.AABB()
+
+	vertical := contact
+	if dy > 0 {
+		vertical.Normal = [2]float32{0, -1}
+		vertical.Depth = maxY - otherMinY
+	} else {
+		vertical.Normal = [2]float32{0, 1}
+		vertical.Depth = otherMaxY - minY
+	}
+
+	// Only solids entered during this move are resolved vertically
+	if vertical.Depth > geom.Abs(dy)+MinimumPenetration {
+		return contact
+	}
+	return vertical
+}
+
+// stepUp retries a horizontal move that hit a wall from StepHeight above,
+// keeping the result only if the character lands on walkable ground further along.
+func (cc *CharacterController) stepUp(dx float32) {
+	if cc.Config.StepHeight <= 0 {
+		return
+	}
+
+	blockedX, blockedY := cc.collider.Position()
+	blocked := cc.contacts
+
+	cc.contacts = CharacterContacts{}
+	cc.moveAxis(0, -cc.Config.StepHeight)
+	if cc.contacts.Ceiling {
+		// No head room to step up
+		cc.collider.SetPosition(blockedX, blockedY)
+		cc.contacts = blocked
+		return
+	}
+
+	_, raisedY := cc.collider.Position()
+	cc.moveAxis(dx, 0)
+	cc.moveAxis(0, blockedY-raisedY)
+
+	steppedX, _ := cc.collider.Position()
+	if !cc.contacts.Ground || math.Abs(float64(steppedX-blockedX)) <= float64(Epsilon) {
+		cc.collider.SetPosition(blockedX, blockedY)
+		cc.contacts = blocked
+	}
+}
+
+// snapToGround probes below a grounded character that did not touch ground during its move, so it stays grounded
+// while resting, riding a platform or walking down a slope. The probe reaches GroundCheckDistance, and SnapDistance
+// while walking, but the character is only pulled down as far as the slope it walked off, or the slope it lands on,
+// descends over the distance moved. Walking off a ledge is not snapped, so the character falls.
+func (cc *CharacterController) snapToGround(dx float32, lastNormal [2]float32) {
+	probe := cc.world.config.GroundCheckDistance
+	if dx != 0 {
+		probe = max(probe, cc.Config.SnapDistance)
+	}
+	if probe <= 0 {
+		return
+	}
+
+	x, y := cc.collider.Position()
+	contacts := cc.contacts
+
+	cc.moveAxis(0, probe)
+
+	// Ground is measured below the collider's center, which may be up to half its width past the slope's edge
+	minX, _, maxX, _ := cc.collider.AABB()
+	reach := geom.Abs(dx) + (maxX-minX)/2
+
+	_, snappedY := cc.collider.Position()
+	drop := max(slopeDrop(lastNormal, reach), slopeDrop(cc.contacts.GroundNormal, reach), cc.world.config.GroundCheckDistance)
+	if !cc.contacts.Ground || snappedY-y > drop+MinimumPenetration {
+		cc.collider.SetPosition(x, y)
+		cc.contacts = contacts
+	}
+}
+
+// slopeDrop returns how far ground with the given normal descends over a horizontal distance.
+func slopeDrop(normal [2]float32, distance float32) float32 {
+	if normal[1] == 0 {
+		return 0
+	}
+	return geom.Abs(distance * normal[0] / normal[1])
+}
+
+// deepestContact returns the deepest overlap between the character and the world's solids.
+func (cc *CharacterController) deepestContact() (Collision, bool) {
+	info := cc.collider.Info()
+
+	var deepest Collision
+	found := false
+
+	others := cc.world.querySolids(cc.collider.AABB())
+	for i := range others {
+		otherInfo := others[i].Info()
+		if otherInfo.Mode == CollisionModeIgnore || !ShouldCollide(info.Layer, otherInfo.Layer) {
+			continue
+		}
+
+		contact, overlaps := cc.world.checkOverlap(cc.collider, others[i])
+		if overlaps && otherInfo.IsOneWay() {
+			if cc.moveY < 0 {
+				continue
+			}
+			contact, overlaps = cc.world.oneWayContact(cc.collider, info, others[i], contact, cc.startBottom)
+		}
+		if overlaps && contact.Depth > MinimumPenetration && (!found || contact.Depth > deepest.Depth) {
+			deepest = contact
+			found = true
+		}
+	}
+
+	return deepest, found
+}
+
+// resolve moves the character out of a contact and records the touched surface.
+func (cc *CharacterController) resolve(contact *Collision) {
+	x, y := cc.collider.Position()
+	normal := contact.Normal
+
+	switch {
+	case walkable(normal, cc.maxSlopeAngle()):
+		// Resolve walkable ground vertically so the character does not slide down slopes.
+		// Box versus triangle contacts already measure depth vertically at the box's bottom center.
+		push := contact.Depth / -normal[1]
+		if _, ok := contact.other.(*TriangleCollider); ok && cc.collider.Info().Type == ColliderTypeBox {
+			push = contact.Depth
+		}
+		cc.collider.SetPosition(x, y-push)
+		cc.contacts.Ground = true
+		cc.contacts.GroundNormal = normal
+		cc.contacts.ground = contact.other
+
+	case walkable([2]float32{normal[0], -normal[1]}, cc.maxSlopeAngle()):
+		cc.collider.SetPosition(x+normal[0]*contact.Depth, y+normal[1]*contact.Depth)
+		cc.contacts.Ceiling = true
+		cc.contacts.CeilingNormal = normal
+
+	default:
+		cc.collider.SetPosition(x+normal[0]*contact.Depth, y+normal[1]*contact.Depth)
+		cc.contacts.Wall = true
+		cc.contacts.WallNormal = normal
+	}
+}
+
+// maxSlopeAngle returns the steepest slope the character walks on, shared with the world unless overridden.
+func (cc *CharacterController) maxSlopeAngle() float32 {
+	if cc.Config.MaxSlopeAngle > 0 {
+		return cc.Config.MaxSlopeAngle
+	}
+	return cc.world.config.MaxSlopeAngle
+}
@@ -0,0 +1,143 @@
+package physics
+
+import (
+	"testing"
+
+	"github.com/adm87/deepdown/scripts/deepdown"
+)
+
+const testStep = 1.0 / 60.0
+
+func newTestWorld() *World {
+	return NewWorld(deepdown.NewContext(), DefaultConfig())
+}
+
+func addSolid(w *World, c Collider, role Role) Collider {
+	c.Info().Role = role
+	w.AddCollider(c)
+	return c
+}
+
+func addCharacter(w *World, x, y, width, height float32) *CharacterController {
+	collider := w.Pool().GetBoxCollider(x, y, width, height)
+	collider.State = ColliderStateDynamic
+	cc := NewCharacterController(collider, DefaultCharacterConfig())
+	w.AddCharacter(cc)
+	return cc
+}
+
+func stepWorld(w *World) {
+	w.Update(testStep, -1000, -1000, 1000, 1000)
+}
+
+func TestCharacterStaysGrounded(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     Role
+		velocity [2]float32 // Velocity of the kinematic ground, zero for static ground
+		oneWay   bool
+	}{
+		{name: "idle on floor", role: CollisionRoleFloor | CollisionRoleWall},
+		{name: "idle on one-way platform", role: CollisionRoleOneWay, oneWay: true},
+		{name: "riding platform right", role: CollisionRoleFloor | CollisionRoleWall, velocity: [2]float32{30, 0}},
+		{name: "riding platform down", role: CollisionRoleFloor | CollisionRoleWall, velocity: [2]float32{0, 20}},
+		{name: "riding platform up", role: CollisionRoleFloor | CollisionRoleWall, velocity: [2]float32{0, -20}},
+		{name: "riding one-way platform left", role: CollisionRoleOneWay, velocity: [2]float32{-30, 0}, oneWay: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld()
+
+			ground := w.Pool().GetBoxCollider(0, 100, 200, 16)
+			if tt.velocity != [2]float32{} {
+				ground.State = ColliderStateKinematic
+				ground.Velocity = tt.velocity
+			}
+			addSolid(w, ground, tt.role)
+
+			cc := addCharacter(w, 96, 92, 4, 8)
+			info := cc.collider.Info()
+
+			// Let the character settle onto the ground
+			for range 30 {
+				if stepWorld(w); info.OnGround {
+					break
+				}
+			}
+			startX, _ := cc.collider.Position()
+
+			steps := 60
+			for step := range steps {
+				stepWorld(w)
+				if !info.OnGround {
+					t.Fatalf("step %d: character left the ground", step)
+				}
+				if info.Ground() == nil || !info.Ground().Equals(ground) {
+					t.Fatalf("step %d: character is not on the ground collider", step)
+				}
+				if info.OnOneWayPlatform() != tt.oneWay {
+					t.Fatalf("step %d: OnOneWayPlatform = %v, want %v", step, info.OnOneWayPlatform(), tt.oneWay)
+				}
+			}
+
+			// Riders are carried the full distance travelled by their ground
+			x, y := cc.collider.Position()
+			_, groundY := ground.Position()
+			if want := startX + tt.velocity[0]*float32(steps)*testStep; !near(x, want, 0.01) {
+				t.Errorf("x = %.3f, want %.3f", x, want)
+			}
+			if want := groundY - 8; !near(y, want, 0.01) {
+				t.Errorf("y = %.3f, want %.3f resting on the ground", y, want)
+			}
+		})
+	}
+}
+
+func TestCharacterFallsOffLedge(t *testing.T) {
+	w := newTestWorld()
+	addSolid(w, w.Pool().GetBoxCollider(0, 100, 100, 16), CollisionRoleFloor|CollisionRoleWall)
+	addSolid(w, w.Pool().GetBoxCollider(100, 108, 100, 16), CollisionRoleFloor|CollisionRoleWall)
+
+	cc := addCharacter(w, 90, 92, 4, 8)
+	info := cc.collider.Info()
+
+	for range 120 {
+		info.Velocity[0] = 30
+		_, before := cc.collider.Position()
+		stepWorld(w)
+		_, after := cc.collider.Position()
+
+		if after-before > cc.Config.SnapDistance/2 {
+			t.Fatalf("character dropped %.2f in a single step", after-before)
+		}
+	}
+
+	if _, y := cc.collider.Position(); !info.OnGround || !near(y, 100, 0.01) {
+		t.Errorf("character at y = %.2f, grounded %v, want resting on the lower floor", y, info.OnGround)
+	}
+}
+
+func TestCharacterFollowsDescendingSlope(t *testing.T) {
+	w := newTestWorld()
+	floor := CollisionRoleFloor | CollisionRoleWall
+	addSolid(w, w.Pool().GetBoxCollider(0, 100, 100, 16), floor)
+	addSolid(w, w.Pool().GetTriangleCollider(100, 100, [6]float32{0, 0, 40, 40, 0, 40}), floor)
+	addSolid(w, w.Pool().GetBoxCollider(140, 140, 100, 16), floor)
+
+	cc := addCharacter(w, 90, 92, 4, 8)
+	info := cc.collider.Info()
+
+	stepWorld(w)
+	for step := range 150 {
+		info.Velocity[0] = 30
+		stepWorld(w)
+		if x, _ := cc.collider.Position(); !info.OnGround {
+			t.Fatalf("step %d: character left the slope at x = %.2f", step, x)
+		}
+	}
+}
+
+func near(a, b, tolerance float32) bool {
+	return a-b <= tolerance && b-a <= tolerance
+}
@@ -12,10 +12,22 @@ type Collider interface {
 	SetPosition(x, y float32)               // Sets current position
 }
 
+// Shape is implemented by colliders that can be tested with the separating axis theorem.
+// Hull returns the collider's convex hull in world space, inflated by a radius.
+// The returned vertices are owned by the collider and only valid until the next call.
+type Shape interface {
+	Collider
+	Hull() (vertices [][2]float32, radius float32)
+}
+
 type ColliderInfo struct {
 	Movement
 
-	id    uint32
+	id         uint32        // Unique within the pool that allocated the collider
+	generation uint32        // Incremented each time the collider is released
+	released   bool          // Set while the collider is back in its pool
+	pool       *ColliderPool // Pool that allocated the collider
+
 	Layer Layer
 	State State
 	Role  Role
@@ -23,9 +35,23 @@ type ColliderInfo struct {
 	Mode  Mode
 
 	OnGround bool
+	Climbing bool // Suspends gravity while in a climbable volume, cleared when the collider leaves it
 	Offset   [2]float32
+	Path     *Path // Optional path driving a kinematic collider
+	Body     BodyConfig
+	Material Material
 
 	timeSinceLeftGround float32
+	dropThroughTime     float32
+	onOneWay            bool
+	sleeping            bool
+	restSteps           int32
+
+	volumes      Role    // Roles of the volumes overlapping the collider
+	submerged    float32 // Fraction of the collider's height below the water surface
+	waterSurface float32 // Height of the highest water surface overlapping the collider
+
+	ground Collider
 
 	collisions []Collision
 }
@@ -38,15 +64,65 @@ func (ci *ColliderInfo) IsWall() bool {
 	return ci.Role&CollisionRoleWall != 0
 }
 
+func (ci *ColliderInfo) IsOneWay() bool {
+	return ci.Role&CollisionRoleOneWay != 0
+}
+
+// IsVolume returns true if bodies move through the collider and are affected by it instead of colliding.
+func (ci *ColliderInfo) IsVolume() bool {
+	return ci.Role&CollisionRoleVolume != 0
+}
+
 func (ci *ColliderInfo) TimeSinceLeftGround() float32 {
 	return ci.timeSinceLeftGround
 }
 
+// Ground returns the collider this collider is standing on, or nil when airborne.
+func (ci *ColliderInfo) Ground() Collider {
+	if !ci.OnGround {
+		return nil
+	}
+	return ci.ground
+}
+
+// OnOneWayPlatform returns true if the collider is grounded on a one-way platform.
+func (ci *ColliderInfo) OnOneWayPlatform() bool {
+	return ci.OnGround && ci.onOneWay
+}
+
+// DropThrough makes the collider ignore one-way platforms for OneWayDropThroughTime,
+// letting it fall through the platform it is standing on.
+func (ci *ColliderInfo) DropThrough() {
+	ci.dropThroughTime = OneWayDropThroughTime
+	ci.OnGround = false
+	ci.onOneWay = false
+}
+
+// IsDroppingThrough returns true while the collider is ignoring one-way platforms.
+func (ci *ColliderInfo) IsDroppingThrough() bool {
+	return ci.dropThroughTime > 0
+}
+
+// IsSleeping returns true if the collider has come to rest and is skipped by the simulation.
+func (ci *ColliderInfo) IsSleeping() bool {
+	return ci.sleeping
+}
+
+// Wake resumes simulation of a sleeping collider.
+func (ci *ColliderInfo) Wake() {
+	ci.sleeping = false
+	ci.restSteps = 0
+}
+
 type Movement struct {
 	Velocity [2]float32 // Velocity
 
+	force   [2]float32 // Forces accumulated for the next step
+	impulse [2]float32 // Impulses accumulated for the next step
+
 	nextPosition [2]float32 // Next position
-	prevPosition [2]float32 // Previous position
+	prevPosition [2]float32 // Position at the start of the step the collider last moved in
+	prevStep     uint64     // Step in which prevPosition was recorded
 }
 
 type Collision struct {
@@ -64,6 +140,15 @@ const (
 	CollisionRoleNone  Role = 0
 	CollisionRoleWall  Role = 1 << 0
 	CollisionRoleFloor Role = 1 << 1
+	// CollisionRoleOneWay marks a platform that only collides with bodies landing on it from above.
+	CollisionRoleOneWay Role = 1 << 2
+	// CollisionRoleWater marks a volume that applies buoyancy, drag and reduced gravity to bodies inside it.
+	CollisionRoleWater Role = 1 << 3
+	// CollisionRoleClimbable marks a volume, such as a ladder or vines, that bodies can climb without gravity.
+	CollisionRoleClimbable Role = 1 << 4
+
+	// CollisionRoleVolume holds the roles of colliders that bodies move through instead of colliding with.
+	CollisionRoleVolume = CollisionRoleWater | CollisionRoleClimbable
 )
 
 func (cr Role) String() string {
@@ -82,6 +167,27 @@ func (cr Role) String() string {
 			result = "Floor"
 		}
 	}
+	if cr&CollisionRoleOneWay != 0 {
+		if result != "" {
+			result += "|OneWay"
+		} else {
+			result = "OneWay"
+		}
+	}
+	if cr&CollisionRoleWater != 0 {
+		if result != "" {
+			result += "|Water"
+		} else {
+			result = "Water"
+		}
+	}
+	if cr&CollisionRoleClimbable != 0 {
+		if result != "" {
+			result += "|Climbable"
+		} else {
+			result = "Climbable"
+		}
+	}
 
 	if result == "" {
 		return "Unknown"
@@ -90,7 +196,7 @@ func (cr Role) String() string {
 }
 
 func (cr Role) IsValid() bool {
-	const allRoles = CollisionRoleWall | CollisionRoleFloor
+	const allRoles = CollisionRoleWall | CollisionRoleFloor | CollisionRoleOneWay | CollisionRoleWater | CollisionRoleClimbable
 	return cr <= allRoles
 }
 
@@ -100,19 +206,33 @@ type Type uint8
 
 const (
 	ColliderTypeBox Type = iota
+	ColliderTypeTriangle
+	ColliderTypePolygon
+	ColliderTypeCircle
+	ColliderTypeCapsule
+
+	MaxColliderTypes = 16
 )
 
 func (ct Type) String() string {
 	switch ct {
 	case ColliderTypeBox:
 		return "Box"
+	case ColliderTypeTriangle:
+		return "Triangle"
+	case ColliderTypePolygon:
+		return "Polygon"
+	case ColliderTypeCircle:
+		return "Circle"
+	case ColliderTypeCapsule:
+		return "Capsule"
 	default:
 		return "Unknown"
 	}
 }
 
 func (ct Type) IsValid() bool {
-	return ct <= ColliderTypeBox
+	return ct <= ColliderTypeCapsule
 }
 
 // =========== Collider State ==========
@@ -123,6 +243,8 @@ const (
 	ColliderStateStatic State = iota
 	ColliderStateDynamic
 	ColliderStateTrigger
+	ColliderStateKinematic
+	ColliderStateCharacter
 )
 
 func (cs State) String() string {
@@ -133,13 +255,17 @@ func (cs State) String() string {
 		return "Dynamic"
 	case ColliderStateTrigger:
 		return "Trigger"
+	case ColliderStateKinematic:
+		return "Kinematic"
+	case ColliderStateCharacter:
+		return "Character"
 	default:
 		return "Unknown"
 	}
 }
 
 func (cs State) IsValid() bool {
-	return cs <= ColliderStateTrigger
+	return cs <= ColliderStateCharacter
 }
 
 // =========== Collision Mode ==========
@@ -174,6 +300,8 @@ func (cm Mode) IsValid() bool {
 type BoxCollider struct {
 	ColliderInfo
 	geom.Rectangle
+
+	hull [4][2]float32
 }
 
 func (bc *BoxCollider) AABB() (minX, minY, maxX, maxY float32) {
@@ -184,6 +312,12 @@ func (bc *BoxCollider) AABB() (minX, minY, maxX, maxY float32) {
 	return
 }
 
+func (bc *BoxCollider) Hull() ([][2]float32, float32) {
+	minX, minY, maxX, maxY := bc.AABB()
+	bc.hull = [4][2]float32{{minX, minY}, {maxX, minY}, {maxX, maxY}, {minX, maxY}}
+	return bc.hull[:], 0
+}
+
 func (bc *BoxCollider) Equals(other Collider) bool {
 	return bc.ColliderInfo.id == other.Info().id
 }
@@ -197,7 +331,6 @@ func (bc *BoxCollider) Position() (x, y float32) {
 }
 
 func (bc *BoxCollider) SetPosition(x, y float32) {
-	bc.prevPosition[0], bc.prevPosition[1] = bc.X, bc.Y
 	bc.X, bc.Y = x, y
 	bc.nextPosition[0], bc.nextPosition[1] = x, y
 }
@@ -207,6 +340,8 @@ func (bc *BoxCollider) SetPosition(x, y float32) {
 type TriangleCollider struct {
 	ColliderInfo
 	geom.Triangle
+
+	hull [3][2]float32
 }
 
 func (tc *TriangleCollider) AABB() (minX, minY, maxX, maxY float32) {
@@ -225,6 +360,16 @@ func (tc *TriangleCollider) AABB() (minX, minY, maxX, maxY float32) {
 	return
 }
 
+func (tc *TriangleCollider) Hull() ([][2]float32, float32) {
+	x := tc.nextPosition[0] + tc.Offset[0]
+	y := tc.nextPosition[1] + tc.Offset[1]
+	points := tc.Points()
+	for i := range tc.hull {
+		tc.hull[i] = [2]float32{x + points[i*2], y + points[i*2+1]}
+	}
+	return tc.hull[:], 0
+}
+
 func (tc *TriangleCollider) Equals(other Collider) bool {
 	return tc.ColliderInfo.id == other.Info().id
 }
@@ -238,7 +383,142 @@ func (tc *TriangleCollider) Position() (x, y float32) {
 }
 
 func (tc *TriangleCollider) SetPosition(x, y float32) {
-	tc.prevPosition[0], tc.prevPosition[1] = tc.X, tc.Y
 	tc.X, tc.Y = x, y
 	tc.nextPosition[0], tc.nextPosition[1] = x, y
 }
+
+// =========== Polygon Collider ==========
+
+type PolygonCollider struct {
+	ColliderInfo
+	geom.Polygon
+
+	hull [][2]float32
+}
+
+func (pc *PolygonCollider) AABB() (minX, minY, maxX, maxY float32) {
+	minX, minY = pc.Polygon.Min()
+	maxX, maxY = pc.Polygon.Max()
+
+	offsetX := pc.nextPosition[0] - pc.X + pc.Offset[0]
+	offsetY := pc.nextPosition[1] - pc.Y + pc.Offset[1]
+
+	minX += offsetX
+	minY += offsetY
+	maxX += offsetX
+	maxY += offsetY
+	return
+}
+
+func (pc *PolygonCollider) Hull() ([][2]float32, float32) {
+	x := pc.nextPosition[0] + pc.Offset[0]
+	y := pc.nextPosition[1] + pc.Offset[1]
+	pc.hull = pc.hull[:0]
+	for _, p := range pc.Points() {
+		pc.hull = append(pc.hull, [2]float32{x + p[0], y + p[1]})
+	}
+	return pc.hull, 0
+}
+
+func (pc *PolygonCollider) Equals(other Collider) bool {
+	return pc.ColliderInfo.id == other.Info().id
+}
+
+func (pc *PolygonCollider) Info() *ColliderInfo {
+	return &pc.ColliderInfo
+}
+
+func (pc *PolygonCollider) Position() (x, y float32) {
+	return pc.X, pc.Y
+}
+
+func (pc *PolygonCollider) SetPosition(x, y float32) {
+	pc.X, pc.Y = x, y
+	pc.nextPosition[0], pc.nextPosition[1] = x, y
+}
+
+// =========== Circle Collider ==========
+
+// CircleCollider is a circle whose position is its center.
+type CircleCollider struct {
+	ColliderInfo
+	geom.Circle
+
+	hull [1][2]float32
+}
+
+func (cc *CircleCollider) AABB() (minX, minY, maxX, maxY float32) {
+	x := cc.nextPosition[0] + cc.Offset[0]
+	y := cc.nextPosition[1] + cc.Offset[1]
+	return x - cc.Radius, y - cc.Radius, x + cc.Radius, y + cc.Radius
+}
+
+func (cc *CircleCollider) Hull() ([][2]float32, float32) {
+	cc.hull[0] = [2]float32{cc.nextPosition[0] + cc.Offset[0], cc.nextPosition[1] + cc.Offset[1]}
+	return cc.hull[:], cc.Radius
+}
+
+func (cc *CircleCollider) Equals(other Collider) bool {
+	return cc.ColliderInfo.id == other.Info().id
+}
+
+func (cc *CircleCollider) Info() *ColliderInfo {
+	return &cc.ColliderInfo
+}
+
+func (cc *CircleCollider) Position() (x, y float32) {
+	return cc.X, cc.Y
+}
+
+func (cc *CircleCollider) SetPosition(x, y float32) {
+	cc.X, cc.Y = x, y
+	cc.nextPosition[0], cc.nextPosition[1] = x, y
+}
+
+// =========== Capsule Collider ==========
+
+type CapsuleCollider struct {
+	ColliderInfo
+	geom.Capsule
+
+	hull [2][2]float32
+}
+
+func (cc *CapsuleCollider) AABB() (minX, minY, maxX, maxY float32) {
+	minX, minY = cc.Capsule.Min()
+	maxX, maxY = cc.Capsule.Max()
+
+	offsetX := cc.nextPosition[0] - cc.X + cc.Offset[0]
+	offsetY := cc.nextPosition[1] - cc.Y + cc.Offset[1]
+
+	minX += offsetX
+	minY += offsetY
+	maxX += offsetX
+	maxY += offsetY
+	return
+}
+
+func (cc *CapsuleCollider) Hull() ([][2]float32, float32) {
+	x := cc.nextPosition[0] + cc.Offset[0]
+	y := cc.nextPosition[1] + cc.Offset[1]
+	cc.hull[0] = [2]float32{x + cc.Start[0], y + cc.Start[1]}
+	cc.hull[1] = [2]float32{x + cc.End[0], y + cc.End[1]}
+	return cc.hull[:], cc.Radius
+}
+
+func (cc *CapsuleCollider) Equals(other Collider) bool {
+	return cc.ColliderInfo.id == other.Info().id
+}
+
+func (cc *CapsuleCollider) Info() *ColliderInfo {
+	return &cc.ColliderInfo
+}
+
+func (cc *CapsuleCollider) Position() (x, y float32) {
+	return cc.X, cc.Y
+}
+
+func (cc *CapsuleCollider) SetPosition(x, y float32) {
+	cc.X, cc.Y = x, y
+	cc.nextPosition[0], cc.nextPosition[1] = x, y
+}
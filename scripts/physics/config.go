@@ -0,0 +1,99 @@
+package physics
+
+import "math"
+
+// Config holds the physics parameters of a World.
+type Config struct {
+	Gravity              float32        // Downward acceleration applied to bodies
+	MaxVelocityRiseSpeed float32        // Maximum upward velocity (negative)
+	MaxVelocityFallSpeed float32        // Maximum downward velocity
+	VelocityDamping      float32        // Horizontal velocity multiplier applied each step while airborne
+	GroundCheckDistance  float32        // Minimum distance below a body searched for ground
+	GroundCheckTolerance float32        // Penetration allowed when detecting ground
+	Broadphase           BroadphaseType // Broadphase used to find potential collisions
+	GridCellSize         float32        // Cell size of the grid broadphase
+	TreeMargin           float32        // Bounds enlargement of the AABB tree broadphase
+	MaxSlopeAngle        float32        // Steepest slope in degrees bodies can rest on, steeper slopes are slid down
+	SimulationMargin     float32        // Distance beyond the update region in which bodies are still simulated
+	SleepSteps           int32          // Consecutive resting steps before a body sleeps, 0 disables sleeping
+	WaterGravityScale    float32        // Multiplier applied to gravity while in water
+	WaterBuoyancy        float32        // Upward acceleration in water when fully submerged, scaled by the submerged fraction
+	WaterDrag            float32        // Velocity multiplier applied each step while in water
+	ConstraintIterations int32          // Solver iterations per step for distance and rope constraints
+	DebugPools           bool           // Panic when a collider is released twice, released to another world or added after release
+}
+
+// DefaultConfig returns the physics parameters used when a level does not override them.
+func DefaultConfig() Config {
+	return Config{
+		Gravity:              400.0,
+		MaxVelocityRiseSpeed: -150.0,
+		MaxVelocityFallSpeed: 200.0,
+		VelocityDamping:      0.75,
+		GroundCheckDistance:  1.0,
+		GroundCheckTolerance: 0.5,
+		Broadphase:           BroadphaseGrid,
+		GridCellSize:         8.0,
+		TreeMargin:           2.0,
+		MaxSlopeAngle:        50.0,
+		SimulationMargin:     64.0,
+		SleepSteps:           60,
+		WaterGravityScale:    0.25,
+		WaterBuoyancy:        150.0,
+		WaterDrag:            0.9,
+		ConstraintIterations: 8,
+	}
+}
+
+// BodyConfig overrides world physics parameters for a single body.
+// Zero values for VelocityDamping and the max speeds fall back to the world Config.
+type BodyConfig struct {
+	Mass                 float32 // Mass used to scale forces and impulses, 0 is treated as 1
+	GravityScale         float32 // Multiplier applied to the world gravity
+	VelocityDamping      float32 // Airborne horizontal damping override
+	MaxVelocityRiseSpeed float32 // Maximum upward velocity override (negative)
+	MaxVelocityFallSpeed float32 // Maximum downward velocity override
+	AlwaysSimulate       bool    // Simulate the body outside the update region and never let it sleep
+}
+
+// DefaultBodyConfig returns a BodyConfig that uses the world parameters unchanged.
+func DefaultBodyConfig() BodyConfig {
+	return BodyConfig{
+		Mass:         1.0,
+		GravityScale: 1.0,
+	}
+}
+
+func (bc *BodyConfig) inverseMass() float32 {
+	if bc.Mass <= 0 {
+		return 1
+	}
+	return 1 / bc.Mass
+}
+
+// walkable returns true if a surface with the given normal is no steeper than maxSlopeAngle degrees.
+func walkable(normal [2]float32, maxSlopeAngle float32) bool {
+	return -normal[1] >= float32(math.Cos(float64(maxSlopeAngle)*math.Pi/180))
+}
+
+func (bc *BodyConfig) gravity(cfg *Config) float32 {
+	return cfg.Gravity * bc.GravityScale
+}
+
+func (bc *BodyConfig) damping(cfg *Config) float32 {
+	if bc.VelocityDamping != 0 {
+		return bc.VelocityDamping
+	}
+	return cfg.VelocityDamping
+}
+
+func (bc *BodyConfig) speedLimits(cfg *Config) (rise, fall float32) {
+	rise, fall = cfg.MaxVelocityRiseSpeed, cfg.MaxVelocityFallSpeed
+	if bc.MaxVelocityRiseSpeed != 0 {
+		rise = bc.MaxVelocityRiseSpeed
+	}
+	if bc.MaxVelocityFallSpeed != 0 {
+		fall = bc.MaxVelocityFallSpeed
+	}
+	return
+}
@@ -0,0 +1,310 @@
+package physics
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// =========== Constraint Type ==========
+
+type ConstraintType uint8
+
+const (
+	// ConstraintDistance keeps both ends at an exact distance, like a rigid rod.
+	ConstraintDistance ConstraintType = iota
+	// ConstraintRope keeps both ends within a maximum distance, letting them move closer freely.
+	ConstraintRope
+	// ConstraintSpring pulls both ends towards a rest distance with a stiffness and damping.
+	ConstraintSpring
+)
+
+func (ct ConstraintType) String() string {
+	switch ct {
+	case ConstraintDistance:
+		return "Distance"
+	case ConstraintRope:
+		return "Rope"
+	case ConstraintSpring:
+		return "Spring"
+	default:
+		return "Unknown"
+	}
+}
+
+func (ct ConstraintType) IsValid() bool {
+	return ct <= ConstraintSpring
+}
+
+// =========== Constraint ==========
+
+// ConstraintEnd is one end of a constraint, either a point on a collider or a fixed point in the world.
+type ConstraintEnd struct {
+	Collider Collider   // Attached collider, nil for a fixed point
+	Offset   [2]float32 // Point relative to the collider's position, or the world point when Collider is nil
+}
+
+// AttachTo returns a constraint end on a collider, offset from its position.
+func AttachTo(collider Collider, offsetX, offsetY float32) ConstraintEnd {
+	return ConstraintEnd{Collider: collider, Offset: [2]float32{offsetX, offsetY}}
+}
+
+// AnchorAt returns a constraint end fixed at a point in the world.
+func AnchorAt(x, y float32) ConstraintEnd {
+	return ConstraintEnd{Offset: [2]float32{x, y}}
+}
+
+// Point returns the current world position of the constraint end.
+func (ce *ConstraintEnd) Point() (x, y float32) {
+	if ce.Collider == nil {
+		return ce.Offset[0], ce.Offset[1]
+	}
+	info := ce.Collider.Info()
+	return info.nextPosition[0] + ce.Offset[0], info.nextPosition[1] + ce.Offset[1]
+}
+
+// Constraint links two ends so the world keeps them at, or within, a distance of each other.
+//
+// Distance and rope constraints are solved iteratively after collision resolution, by moving the ends
+// and removing the velocity that stretches them. Springs apply forces at the start of each step instead.
+// Only dynamic and character bodies are moved; static and kinematic colliders and fixed points act as anchors.
+type Constraint struct {
+	Type   ConstraintType
+	A, B   ConstraintEnd
+	Length float32 // Exact distance, maximum rope length or spring rest length
+
+	Stiffness float32 // Spring force per unit of stretch
+	Damping   float32 // Spring force per unit of relative speed along the spring
+}
+
+// NewDistanceConstraint links two ends at a fixed distance, like a rigid rod.
+func NewDistanceConstraint(a, b ConstraintEnd, length float32) *Constraint {
+	return &Constraint{Type: ConstraintDistance, A: a, B: b, Length: length}
+}
+
+// NewRopeConstraint links two ends with a rope of the given length.
+func NewRopeConstraint(a, b ConstraintEnd, length float32) *Constraint {
+	return &Constraint{Type: ConstraintRope, A: a, B: b, Length: length}
+}
+
+// NewSpringConstraint links two ends with a spring.
+func NewSpringConstraint(a, b ConstraintEnd, restLength, stiffness, damping float32) *Constraint {
+	return &Constraint{
+		Type:      ConstraintSpring,
+		A:         a,
+		B:         b,
+		Length:    restLength,
+		Stiffness: stiffness,
+		Damping:   damping,
+	}
+}
+
+// AddConstraint adds a constraint to the world. Its colliders must already be in the world.
+func (w *World) AddConstraint(c *Constraint) {
+	w.constraints = append(w.constraints, c)
+	c.wake()
+}
+
+// RemoveConstraint removes a constraint from the world.
+func (w *World) RemoveConstraint(c *Constraint) {
+	w.constraints = slices.DeleteFunc(w.constraints, func(other *Constraint) bool {
+		return other == c
+	})
+	c.wake()
+}
+
+// Constraints returns the constraints in the world.
+func (w *World) Constraints() []*Constraint {
+	return w.constraints
+}
+
+func (c *Constraint) attachedTo(collider Collider) bool {
+	return (c.A.Collider != nil && c.A.Collider.Equals(collider)) || (c.B.Collider != nil && c.B.Collider.Equals(collider))
+}
+
+func (c *Constraint) wake() {
+	if c.A.Collider != nil {
+		c.A.Collider.Info().Wake()
+	}
+	if c.B.Collider != nil {
+		c.B.Collider.Info().Wake()
+	}
+}
+
+// axis returns the direction from end A to end B and the distance between them.
+func (c *Constraint) axis() (normal [2]float32, distance float32) {
+	ax, ay := c.A.Point()
+	bx, by := c.B.Point()
+	dx, dy := bx-ax, by-ay
+
+	distance = float32(math.Hypot(float64(dx), float64(dy)))
+	if distance < Epsilon {
+		return [2]float32{0, 1}, distance
+	}
+	return [2]float32{dx / distance, dy / distance}, distance
+}
+
+// =========== Solver ==========
+
+// applySpringForces adds the force of every spring to the bodies at its ends.
+// Dynamic bodies outside the simulation region are not integrated, so they receive no force
+// instead of accumulating it until they are simulated again.
+func (w *World) applySpringForces(minX, minY, maxX, maxY float32) {
+	for _, c := range w.constraints {
+		if c.Type != ConstraintSpring {
+			continue
+		}
+
+		normal, distance := c.axis()
+		velA, velB := endVelocity(&c.A), endVelocity(&c.B)
+		relative := (velB[0]-velA[0])*normal[0] + (velB[1]-velA[1])*normal[1]
+
+		force := c.Stiffness*(distance-c.Length) + c.Damping*relative
+		if math.Abs(float64(force)) < float64(Epsilon) {
+			continue
+		}
+
+		if w.endSimulated(&c.A, minX, minY, maxX, maxY) {
+			c.A.Collider.Info().AddForce(normal[0]*force, normal[1]*force)
+		}
+		if w.endSimulated(&c.B, minX, minY, maxX, maxY) {
+			c.B.Collider.Info().AddForce(-normal[0]*force, -normal[1]*force)
+		}
+	}
+}
+
+// solveConstraints corrects the next positions and velocities of bodies held by distance and rope constraints.
+// Characters are moved by their controllers so they still slide along solids.
+func (w *World) solveConstraints(activeBodies []Collider) {
+	if len(w.constraints) == 0 {
+		return
+	}
+
+	// Characters move through their controller once every constraint is solved
+	for _, cc := range w.characters {
+		x, y := cc.collider.Position()
+		cc.collider.Info().nextPosition = [2]float32{x, y}
+	}
+
+	for range max(w.config.ConstraintIterations, 1) {
+		for _, c := range w.constraints {
+			if c.Type != ConstraintSpring {
+				w.solveConstraint(c, activeBodies)
+			}
+		}
+	}
+
+	for _, cc := range w.characters {
+		info := cc.collider.Info()
+		x, y := cc.collider.Position()
+		dx, dy := info.nextPosition[0]-x, info.nextPosition[1]-y
+
+		info.nextPosition = [2]float32{x, y}
+		if dx != 0 || dy != 0 {
+			cc.Move(dx, dy)
+		}
+	}
+}
+
+// solveConstraint runs a single solver iteration of a distance or rope constraint.
+func (w *World) solveConstraint(c *Constraint, activeBodies []Collider) {
+	normal, distance := c.axis()
+
+	stretch := distance - c.Length
+	if c.Type == ConstraintRope && stretch <= 0 {
+		return
+	}
+
+	wA := w.endInverseMass(&c.A, activeBodies)
+	wB := w.endInverseMass(&c.B, activeBodies)
+	total := wA + wB
+	if total <= 0 {
+		return
+	}
+
+	// Move both ends along the axis in proportion to their inverse mass
+	correction := stretch / total
+	moveEnd(&c.A, normal[0]*correction*wA, normal[1]*correction*wA)
+	moveEnd(&c.B, -normal[0]*correction*wB, -normal[1]*correction*wB)
+
+	// Remove the relative velocity stretching the constraint, or any relative velocity along a rod
+	velA, velB := endVelocity(&c.A), endVelocity(&c.B)
+	relative := (velB[0]-velA[0])*normal[0] + (velB[1]-velA[1])*normal[1]
+	if c.Type == ConstraintRope && relative <= 0 {
+		return
+	}
+
+	impulse := relative / total
+	if wA > 0 {
+		info := c.A.Collider.Info()
+		info.Velocity[0] += normal[0] * impulse * wA
+		info.Velocity[1] += normal[1] * impulse * wA
+	}
+	if wB > 0 {
+		info := c.B.Collider.Info()
+		info.Velocity[0] -= normal[0] * impulse * wB
+		info.Velocity[1] -= normal[1] * impulse * wB
+	}
+}
+
+// endMovable returns true if the end is attached to a body the world moves.
+func (w *World) endMovable(end *ConstraintEnd) bool {
+	if end.Collider == nil {
+		return false
+	}
+	state := end.Collider.Info().State
+	return state == ColliderStateDynamic || state == ColliderStateCharacter
+}
+
+// endSimulated returns true if an end is movable and integrated this step. Characters are always integrated,
+// dynamic bodies only within the simulation region or when always simulated.
+func (w *World) endSimulated(end *ConstraintEnd, minX, minY, maxX, maxY float32) bool {
+	if !w.endMovable(end) {
+		return false
+	}
+
+	info := end.Collider.Info()
+	if info.State != ColliderStateDynamic || info.Body.AlwaysSimulate {
+		return true
+	}
+
+	margin := w.config.SimulationMargin
+	bMinX, bMinY, bMaxX, bMaxY := end.Collider.AABB()
+	return bMaxX >= minX-margin && bMinX <= maxX+margin && bMaxY >= minY-margin && bMinY <= maxY+margin
+}
+
+// endInverseMass returns the inverse mass of an end, or 0 if the end cannot be moved this step.
+// Sleeping bodies and bodies outside the simulation region are woken so they join the next step.
+func (w *World) endInverseMass(end *ConstraintEnd, activeBodies []Collider) float32 {
+	if !w.endMovable(end) {
+		return 0
+	}
+
+	info := end.Collider.Info()
+	if info.State == ColliderStateDynamic {
+		_, active := slices.BinarySearchFunc(activeBodies, info.id, func(body Collider, id uint32) int {
+			return cmp.Compare(body.Info().id, id)
+		})
+		if !active {
+			info.Wake()
+			return 0
+		}
+	}
+	return info.Body.inverseMass()
+}
+
+func endVelocity(end *ConstraintEnd) [2]float32 {
+	if end.Collider == nil {
+		return [2]float32{}
+	}
+	return end.Collider.Info().Velocity
+}
+
+func moveEnd(end *ConstraintEnd, dx, dy float32) {
+	if end.Collider == nil || (dx == 0 && dy == 0) {
+		return
+	}
+	info := end.Collider.Info()
+	info.nextPosition[0] += dx
+	info.nextPosition[1] += dy
+}
@@ -1,154 +1,295 @@
 package physics
 
 import (
+	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/adm87/deepdown/scripts/geom"
 )
 
-// Note: colliderIDCounter and its associated used within the physics package is for internal use only.
-// Do not modify or access it directly from outside this package.
-// It is not recommended to use this ID for game logic or other purposes.
-var colliderIDCounter uint32 = 1
+// ColliderPool allocates and recycles the colliders of a single World.
+//
+// Collider IDs are allocated atomically when a collider is first created and stay with it while it is recycled,
+// so two colliders alive in the same pool never share an ID. IDs are only unique within a pool.
+// Each release increments the collider's generation, letting a ColliderHandle detect use after release.
+//
+// In debug mode, releasing a collider twice or releasing it to another world's pool panics.
+// Otherwise such releases are ignored.
+type ColliderPool struct {
+	nextID atomic.Uint32
+	debug  bool
 
-func nextColliderID() (id uint32) {
-	id = colliderIDCounter
-	colliderIDCounter++
-	return
+	boxes     sync.Pool
+	triangles sync.Pool
+	polygons  sync.Pool
+	circles   sync.Pool
+	capsules  sync.Pool
 }
 
-// ClearColliderPools clears all pools causing all unused colliders to be garbage collected.
-// Any colliders still in use will not be garbage collected.
-func ClearColliderPools() {
-	colliderIDCounter = 1
+func NewColliderPool(debug bool) *ColliderPool {
+	p := &ColliderPool{debug: debug}
+	p.nextID.Store(1)
+	p.Clear()
+	return p
+}
 
-	boxColliderPool = newBoxColliderPool()
-	triangleColliderPool = newTriangleColliderPool()
+// Clear drops all unused colliders so they can be garbage collected.
+// Colliders still in use are unaffected, and IDs keep increasing so they are never handed out twice.
+func (p *ColliderPool) Clear() {
+	p.boxes = sync.Pool{New: func() any {
+		bc := &BoxCollider{}
+		p.initInfo(&bc.ColliderInfo, ColliderTypeBox)
+		return bc
+	}}
+	p.triangles = sync.Pool{New: func() any {
+		tc := &TriangleCollider{}
+		p.initInfo(&tc.ColliderInfo, ColliderTypeTriangle)
+		return tc
+	}}
+	p.polygons = sync.Pool{New: func() any {
+		pc := &PolygonCollider{}
+		p.initInfo(&pc.ColliderInfo, ColliderTypePolygon)
+		return pc
+	}}
+	p.circles = sync.Pool{New: func() any {
+		cc := &CircleCollider{}
+		p.initInfo(&cc.ColliderInfo, ColliderTypeCircle)
+		return cc
+	}}
+	p.capsules = sync.Pool{New: func() any {
+		cc := &CapsuleCollider{}
+		p.initInfo(&cc.ColliderInfo, ColliderTypeCapsule)
+		return cc
+	}}
 }
 
-// ReleaseCollider returns a collider to the appropriate physics collider pool.
-func ReleaseCollider(collider Collider) {
+// Release returns a collider to the appropriate collider pool.
+// The collider must not be used after it is released.
+func (p *ColliderPool) Release(collider Collider) {
 	switch c := collider.(type) {
 	case *BoxCollider:
-		ReleaseBoxCollider(c)
+		p.ReleaseBoxCollider(c)
 	case *TriangleCollider:
-		ReleaseTriangleCollider(c)
+		p.ReleaseTriangleCollider(c)
+	case *PolygonCollider:
+		p.ReleasePolygonCollider(c)
+	case *CircleCollider:
+		p.ReleaseCircleCollider(c)
+	case *CapsuleCollider:
+		p.ReleaseCapsuleCollider(c)
 	default:
 		panic("unknown collider type")
 	}
 }
 
+func (p *ColliderPool) initInfo(info *ColliderInfo, t Type) {
+	*info = ColliderInfo{
+		id:         p.nextID.Add(1) - 1,
+		pool:       p,
+		Layer:      CollisionLayerDefault,
+		Mode:       CollisionModeDiscrete,
+		Body:       DefaultBodyConfig(),
+		Material:   DefaultMaterial(),
+		State:      ColliderStateStatic,
+		Type:       t,
+		collisions: make([]Collision, 0, 4),
+	}
+}
+
+// acquire marks a collider taken from the pool as in use.
+func (p *ColliderPool) acquire(info *ColliderInfo, x, y float32) {
+	info.released = false
+	info.nextPosition = [2]float32{x, y}
+	info.prevPosition = [2]float32{x, y}
+}
+
+// release resets a collider's info before it is returned to the pool.
+// It returns false if the collider must not be pooled because it was already released or belongs to another pool.
+func (p *ColliderPool) release(info *ColliderInfo) bool {
+	switch {
+	case info.released:
+		if p.debug {
+			panic(fmt.Sprintf("collider %d released twice", info.id))
+		}
+		return false
+	case info.pool != p:
+		if p.debug {
+			panic(fmt.Sprintf("collider %d released to another world's pool", info.id))
+		}
+		return false
+	}
+
+	*info = ColliderInfo{
+		id:         info.id,
+		generation: info.generation + 1,
+		released:   true,
+		pool:       p,
+		Layer:      CollisionLayerDefault,
+		Mode:       CollisionModeDiscrete,
+		Body:       DefaultBodyConfig(),
+		Material:   DefaultMaterial(),
+		State:      ColliderStateStatic,
+		Type:       info.Type,
+		collisions: info.collisions[:0],
+	}
+	return true
+}
+
 // =========== Box Colliders ==========
 
-var boxColliderPool = newBoxColliderPool()
-
-func newBoxColliderPool() *sync.Pool {
-	return &sync.Pool{
-		New: func() any {
-			return &BoxCollider{
-				ColliderInfo: ColliderInfo{
-					Movement:   Movement{},
-					id:         nextColliderID(),
-					Layer:      CollisionLayerDefault,
-					Mode:       CollisionModeDiscrete,
-					State:      ColliderStateStatic,
-					Type:       ColliderTypeBox,
-					collisions: make([]Collision, 0, 4),
-				},
-				Rectangle: geom.Rectangle{},
-			}
-		},
-	}
-}
-
-// GetBoxCollider retrieves a BoxCollider from the physics collider pool.
-func GetBoxCollider(x, y, width, height float32) *BoxCollider {
-	bc := boxColliderPool.Get().(*BoxCollider)
+// GetBoxCollider retrieves a BoxCollider from the collider pool.
+func (p *ColliderPool) GetBoxCollider(x, y, width, height float32) *BoxCollider {
+	bc := p.boxes.Get().(*BoxCollider)
+	p.acquire(&bc.ColliderInfo, x, y)
 	bc.X = x
 	bc.Y = y
 	bc.Width = width
 	bc.Height = height
-	bc.Movement.nextPosition[0] = x
-	bc.Movement.nextPosition[1] = y
-	bc.Movement.prevPosition[0] = x
-	bc.Movement.prevPosition[1] = y
 	return bc
 }
 
-// ReleaseBoxCollider returns a BoxCollider to the physics collider pool.
-func ReleaseBoxCollider(bc *BoxCollider) {
+// ReleaseBoxCollider returns a BoxCollider to the collider pool.
+func (p *ColliderPool) ReleaseBoxCollider(bc *BoxCollider) {
 	if bc == nil {
 		panic("cannot release a nil BoxCollider")
 	}
-	bc.X = 0
-	bc.Y = 0
-	bc.Width = 0
-	bc.Height = 0
-	bc.Movement.prevPosition[0] = 0
-	bc.Movement.prevPosition[1] = 0
-	bc.Movement.Velocity[0] = 0
-	bc.Movement.Velocity[1] = 0
-	bc.ColliderInfo.Layer = CollisionLayerDefault
-	bc.ColliderInfo.State = ColliderStateStatic
-	bc.ColliderInfo.Type = ColliderTypeBox
-	bc.ColliderInfo.Mode = CollisionModeDiscrete
-	bc.collisions = bc.collisions[:0]
-	boxColliderPool.Put(bc)
+	if !p.release(&bc.ColliderInfo) {
+		return
+	}
+	bc.Rectangle = geom.Rectangle{}
+	p.boxes.Put(bc)
 }
 
 // =========== Triangle Colliders ==========
 
-var triangleColliderPool = newTriangleColliderPool()
-
-func newTriangleColliderPool() *sync.Pool {
-	return &sync.Pool{
-		New: func() any {
-			return &TriangleCollider{
-				ColliderInfo: ColliderInfo{
-					Movement:   Movement{},
-					id:         nextColliderID(),
-					Layer:      CollisionLayerDefault,
-					Mode:       CollisionModeDiscrete,
-					State:      ColliderStateStatic,
-					Type:       ColliderTypeBox,
-					collisions: make([]Collision, 0, 4),
-				},
-				Triangle: geom.Triangle{},
-			}
-		},
-	}
-}
-
-// GetTriangleCollider retrieves a TriangleCollider from the physics collider pool.
-func GetTriangleCollider(x, y float32, points [6]float32) *TriangleCollider {
-	tc := triangleColliderPool.Get().(*TriangleCollider)
+// GetTriangleCollider retrieves a TriangleCollider from the collider pool.
+func (p *ColliderPool) GetTriangleCollider(x, y float32, points [6]float32) *TriangleCollider {
+	tc := p.triangles.Get().(*TriangleCollider)
+	p.acquire(&tc.ColliderInfo, x, y)
 	tc.SetPoints(points)
 	tc.X = x
 	tc.Y = y
-	tc.Movement.nextPosition[0] = x
-	tc.Movement.nextPosition[1] = y
-	tc.Movement.prevPosition[0] = x
-	tc.Movement.prevPosition[1] = y
 	return tc
 }
 
-func ReleaseTriangleCollider(tc *TriangleCollider) {
+// ReleaseTriangleCollider returns a TriangleCollider to the collider pool.
+func (p *ColliderPool) ReleaseTriangleCollider(tc *TriangleCollider) {
 	if tc == nil {
 		panic("cannot release a nil TriangleCollider")
 	}
+	if !p.release(&tc.ColliderInfo) {
+		return
+	}
 	tc.X = 0
 	tc.Y = 0
 	tc.SetPoints([6]float32{})
-	tc.Movement.nextPosition[0] = 0
-	tc.Movement.nextPosition[1] = 0
-	tc.Movement.prevPosition[0] = 0
-	tc.Movement.prevPosition[1] = 0
-	tc.Movement.Velocity[0] = 0
-	tc.Movement.Velocity[1] = 0
-	tc.ColliderInfo.Layer = CollisionLayerDefault
-	tc.ColliderInfo.State = ColliderStateStatic
-	tc.ColliderInfo.Type = ColliderTypeBox
-	tc.ColliderInfo.Mode = CollisionModeDiscrete
-	tc.collisions = tc.collisions[:0]
-	triangleColliderPool.Put(tc)
+	p.triangles.Put(tc)
+}
+
+// =========== Polygon Colliders ==========
+
+// GetPolygonCollider retrieves a PolygonCollider from the collider pool.
+// The points must form a convex polygon and are relative to x, y.
+func (p *ColliderPool) GetPolygonCollider(x, y float32, points [][2]float32) *PolygonCollider {
+	pc := p.polygons.Get().(*PolygonCollider)
+	p.acquire(&pc.ColliderInfo, x, y)
+	pc.SetPoints(points)
+	pc.X = x
+	pc.Y = y
+	return pc
+}
+
+// ReleasePolygonCollider returns a PolygonCollider to the collider pool.
+func (p *ColliderPool) ReleasePolygonCollider(pc *PolygonCollider) {
+	if pc == nil {
+		panic("cannot release a nil PolygonCollider")
+	}
+	if !p.release(&pc.ColliderInfo) {
+		return
+	}
+	pc.X = 0
+	pc.Y = 0
+	pc.Clear()
+	p.polygons.Put(pc)
+}
+
+// =========== Circle Colliders ==========
+
+// GetCircleCollider retrieves a CircleCollider centered on x, y from the collider pool.
+func (p *ColliderPool) GetCircleCollider(x, y, radius float32) *CircleCollider {
+	cc := p.circles.Get().(*CircleCollider)
+	p.acquire(&cc.ColliderInfo, x, y)
+	cc.X = x
+	cc.Y = y
+	cc.Radius = radius
+	return cc
+}
+
+// ReleaseCircleCollider returns a CircleCollider to the collider pool.
+func (p *ColliderPool) ReleaseCircleCollider(cc *CircleCollider) {
+	if cc == nil {
+		panic("cannot release a nil CircleCollider")
+	}
+	if !p.release(&cc.ColliderInfo) {
+		return
+	}
+	cc.Circle = geom.Circle{}
+	p.circles.Put(cc)
+}
+
+// =========== Capsule Colliders ==========
+
+// GetCapsuleCollider retrieves a CapsuleCollider from the collider pool.
+// The segment start and end points are relative to x, y.
+func (p *ColliderPool) GetCapsuleCollider(x, y float32, start, end [2]float32, radius float32) *CapsuleCollider {
+	cc := p.capsules.Get().(*CapsuleCollider)
+	p.acquire(&cc.ColliderInfo, x, y)
+	cc.X = x
+	cc.Y = y
+	cc.Start = start
+	cc.End = end
+	cc.Radius = radius
+	return cc
+}
+
+// ReleaseCapsuleCollider returns a CapsuleCollider to the collider pool.
+func (p *ColliderPool) ReleaseCapsuleCollider(cc *CapsuleCollider) {
+	if cc == nil {
+		panic("cannot release a nil CapsuleCollider")
+	}
+	if !p.release(&cc.ColliderInfo) {
+		return
+	}
+	cc.Capsule = geom.Capsule{}
+	p.capsules.Put(cc)
+}
+
+// =========== Collider Handles ==========
+
+// ColliderHandle is a weak reference to a collider that detects when it has been released.
+// A released collider may be reused with the same ID, so code holding colliders beyond a step should keep handles.
+type ColliderHandle struct {
+	collider   Collider
+	generation uint32
+}
+
+// NewColliderHandle returns a handle to a collider in use.
+func NewColliderHandle(collider Collider) ColliderHandle {
+	return ColliderHandle{
+		collider:   collider,
+		generation: collider.Info().generation,
+	}
+}
+
+// Get returns the referenced collider, or false if it has been released since the handle was created.
+func (h ColliderHandle) Get() (Collider, bool) {
+	if h.collider == nil {
+		return nil, false
+	}
+	info := h.collider.Info()
+	if info.released || info.generation != h.generation {
+		return nil, false
+	}
+	return h.collider, true
 }
@@ -0,0 +1,36 @@
+package physics
+
+// AddForce accumulates a continuous force, such as wind or a spring, applied over the next fixed step.
+// Forces must be added every step they act on the body.
+func (ci *ColliderInfo) AddForce(fx, fy float32) {
+	ci.force[0] += fx
+	ci.force[1] += fy
+	ci.Wake()
+}
+
+// AddImpulse accumulates an instantaneous change in momentum, such as knockback or an explosion,
+// applied at the start of the next fixed step.
+func (ci *ColliderInfo) AddImpulse(ix, iy float32) {
+	ci.impulse[0] += ix
+	ci.impulse[1] += iy
+	ci.Wake()
+}
+
+// SetVelocity replaces the velocity of the body.
+// Forces and impulses accumulated for the next step are still applied on top of it.
+func (ci *ColliderInfo) SetVelocity(vx, vy float32) {
+	ci.Velocity[0] = vx
+	ci.Velocity[1] = vy
+	ci.Wake()
+}
+
+// integrateForces applies the accumulated forces and impulses to the velocity and clears them.
+func (ci *ColliderInfo) integrateForces(dt float32) {
+	inverseMass := ci.Body.inverseMass()
+
+	ci.Velocity[0] += (ci.impulse[0] + ci.force[0]*dt) * inverseMass
+	ci.Velocity[1] += (ci.impulse[1] + ci.force[1]*dt) * inverseMass
+
+	ci.force = [2]float32{}
+	ci.impulse = [2]float32{}
+}
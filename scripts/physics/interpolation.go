@@ -0,0 +1,31 @@
+package physics
+
+// RenderPosition returns the position of a collider interpolated between the start and end of the last step.
+// Alpha is the fraction of a fixed step accumulated since that step, from 0 to 1.
+// Colliders that did not move in the last step, or were placed with SetPosition, are returned at their position.
+func (w *World) RenderPosition(collider Collider, alpha float32) (x, y float32) {
+	x, y = collider.Position()
+
+	info := collider.Info()
+	if info.prevStep != w.steps || w.steps == 0 {
+		return x, y
+	}
+
+	alpha = clamp(alpha, 0, 1)
+	return info.prevPosition[0] + (x-info.prevPosition[0])*alpha, info.prevPosition[1] + (y-info.prevPosition[1])*alpha
+}
+
+// Steps returns the number of fixed steps the world has taken.
+func (w *World) Steps() uint64 {
+	return w.steps
+}
+
+// savePrevious records the position a collider moves from in the current step.
+// Only the first move of each step is recorded, so a collider moved several times interpolates across all of them.
+func (w *World) savePrevious(info *ColliderInfo, x, y float32) {
+	if info.prevStep == w.steps {
+		return
+	}
+	info.prevPosition = [2]float32{x, y}
+	info.prevStep = w.steps
+}
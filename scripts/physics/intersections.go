@@ -2,24 +2,82 @@ package physics
 
 import "github.com/adm87/deepdown/scripts/geom"
 
-func CheckOverlap(colliderA, colliderB Collider) (Collision, bool) {
-	switch a := colliderA.(type) {
-	case *BoxCollider:
-		switch b := colliderB.(type) {
-		case *BoxCollider:
-			return BoxVsBox(a, b)
-		case *TriangleCollider:
-			return BoxVsTriangle(a, b)
-		}
-	case *TriangleCollider:
-		switch b := colliderB.(type) {
-		case *BoxCollider:
-			return BoxVsTriangle(b, a)
-		case *TriangleCollider:
-			return TriangleVsTriangle(a, b)
+// MinimumPenetration is the overlap depth below which contacts are ignored.
+const MinimumPenetration float32 = 0.01
+
+// OverlapFunc tests two colliders for overlap.
+// The contact normal points away from b, so moving a by Normal*Depth separates them.
+type OverlapFunc func(a, b Collider) (Collision, bool)
+
+var overlapTests [MaxColliderTypes][MaxColliderTypes]OverlapFunc
+
+func init() {
+	RegisterOverlap(ColliderTypeBox, ColliderTypeBox, func(a, b Collider) (Collision, bool) {
+		return BoxVsBox(a.(*BoxCollider), b.(*BoxCollider))
+	})
+	RegisterOverlap(ColliderTypeBox, ColliderTypeTriangle, func(a, b Collider) (Collision, bool) {
+		return BoxVsTriangle(a.(*BoxCollider), b.(*TriangleCollider))
+	})
+}
+
+// RegisterOverlap registers the overlap test used between two collider types.
+// The reversed pair is registered as well, with the contact normal flipped.
+// Pairs without a registered test fall back to ShapeVsShape.
+func RegisterOverlap(typeA, typeB Type, test OverlapFunc) {
+	if typeA >= MaxColliderTypes || typeB >= MaxColliderTypes {
+		panic("collider type exceeds MaxColliderTypes")
+	}
+
+	overlapTests[typeA][typeB] = test
+
+	if typeA != typeB {
+		overlapTests[typeB][typeA] = func(a, b Collider) (Collision, bool) {
+			contact, overlaps := test(b, a)
+			contact.Normal[0], contact.Normal[1] = -contact.Normal[0], -contact.Normal[1]
+			contact.other = b
+			return contact, overlaps
 		}
 	}
-	return Collision{}, false
+}
+
+func CheckOverlap(colliderA, colliderB Collider) (Collision, bool) {
+	if test := overlapTests[colliderA.Info().Type][colliderB.Info().Type]; test != nil {
+		return test(colliderA, colliderB)
+	}
+	return ShapeVsShape(colliderA, colliderB)
+}
+
+// ShapeVsShape tests any two colliders implementing Shape using the separating axis theorem.
+func ShapeVsShape(colliderA, colliderB Collider) (Collision, bool) {
+	var contact Collision
+
+	a, okA := colliderA.(Shape)
+	b, okB := colliderB.(Shape)
+	if !okA || !okB {
+		return contact, false
+	}
+
+	minXA, minYA, maxXA, maxYA := a.AABB()
+	minXB, minYB, maxXB, maxYB := b.AABB()
+
+	// Quick AABB check
+	if minXA >= maxXB || maxXA <= minXB || minYA >= maxYB || maxYA <= minYB {
+		return contact, false
+	}
+
+	hullA, radiusA := a.Hull()
+	hullB, radiusB := b.Hull()
+
+	normal, depth, overlaps := geom.SAT(hullA, radiusA, hullB, radiusB)
+	if !overlaps || depth < MinimumPenetration {
+		return contact, false
+	}
+
+	contact.Normal = normal
+	contact.Depth = depth
+	contact.other = colliderB
+
+	return contact, true
 }
 
 func BoxVsBox(b1, b2 *BoxCollider) (Collision, bool) {
@@ -45,7 +103,7 @@ func BoxVsBox(b1, b2 *BoxCollider) (Collision, bool) {
 
 	// Resolve along axis of least penetration
 	if overlapX < overlapY {
-		if overlapX < 0.01 {
+		if overlapX < MinimumPenetration {
 			return contact, false
 		}
 		// Normal points away from surface (standard convention)
@@ -56,7 +114,7 @@ func BoxVsBox(b1, b2 *BoxCollider) (Collision, bool) {
 		}
 		contact.Depth = overlapX
 	} else {
-		if overlapY < 0.01 {
+		if overlapY < MinimumPenetration {
 			return contact, false
 		}
 		if centerYA < centerYB {
@@ -73,6 +131,10 @@ func BoxVsBox(b1, b2 *BoxCollider) (Collision, bool) {
 	return contact, true
 }
 
+// BoxVsTriangle tests a box against a right-angled triangle.
+// Floor slopes are tested against the box's bottom-center and ceiling slopes against its top-center,
+// with depth measured vertically. Boxes beside the triangle's vertical leg or against its flat side
+// are pushed out along that side instead when it is the shallower way out.
 func BoxVsTriangle(box *BoxCollider, tri *TriangleCollider) (Collision, bool) {
 	var contact Collision
 
@@ -84,42 +146,62 @@ func BoxVsTriangle(box *BoxCollider, tri *TriangleCollider) (Collision, bool) {
 		return contact, false
 	}
 
-	// Get triangle properties
-	slope := tri.Slope()
+	normal := tri.SlopeNormal()
 	corner := tri.Corner()
-
-	// Calculate box bottom-center
 	boxCenterX := (minXA + maxXA) * 0.5
-	boxBottomY := maxYA
 
-	// Check if bottom-center is within triangle's X range
-	triMinX := tri.X + min(slope[0][0], slope[1][0], corner[0])
-	triMaxX := tri.X + max(slope[0][0], slope[1][0], corner[0])
+	// The vertical leg is on the same side as the right-angle corner
+	var legDepth float32
+	var legNormal [2]float32
+	if corner[0] >= maxXB-Epsilon {
+		legDepth, legNormal = maxXB-minXA, [2]float32{1, 0}
+	} else {
+		legDepth, legNormal = maxXA-minXB, [2]float32{-1, 0}
+	}
 
-	if boxCenterX < triMinX || boxCenterX > triMaxX {
-		return contact, false
+	contact.Depth = -1
+	candidate := func(n [2]float32, depth float32) {
+		if depth > 0 && (contact.Depth < 0 || depth < contact.Depth) {
+			contact.Normal = n
+			contact.Depth = depth
+		}
 	}
 
-	// Find the Y position on the slope at the box's center X
-	surfaceY, found := geom.FindTriangleSurfaceAt(boxCenterX, &tri.Triangle)
-	if !found {
-		return contact, false
+	if boxCenterX >= minXB && boxCenterX <= maxXB {
+		surfaceY, found := geom.FindTriangleSurfaceAt(boxCenterX, &tri.Triangle)
+		if !found {
+			return Collision{}, false
+		}
+
+		if normal[1] < 0 {
+			// Floor slope, solid below the surface
+			if maxYA <= surfaceY {
+				return Collision{}, false
+			}
+			candidate(normal, maxYA-surfaceY)
+			candidate([2]float32{0, 1}, maxYB-minYA)
+		} else {
+			// Ceiling slope, solid above the surface
+			if minYA >= surfaceY {
+				return Collision{}, false
+			}
+			candidate(normal, surfaceY-minYA)
+			candidate([2]float32{0, -1}, maxYA-minYB)
+		}
+		candidate(legNormal, legDepth)
+	} else if (legNormal[0] > 0 && boxCenterX > maxXB) || (legNormal[0] < 0 && boxCenterX < minXB) {
+		// Side-on hit against the vertical leg
+		candidate(legNormal, legDepth)
 	}
 
-	// Check if box penetrates the slope
-	if boxBottomY > surfaceY {
-		// Penetrating
-		contact.Depth = boxBottomY - surfaceY
-		contact.Normal = tri.SlopeNormal()
-		contact.other = tri
-		return contact, true
+	if contact.Depth < 0 {
+		return Collision{}, false
 	}
 
-	return contact, false
+	contact.other = tri
+	return contact, true
 }
 
 func TriangleVsTriangle(t1, t2 *TriangleCollider) (Collision, bool) {
-	var contact Collision
-
-	return contact, false
+	return ShapeVsShape(t1, t2)
 }
@@ -0,0 +1,123 @@
+package physics
+
+import "github.com/adm87/deepdown/scripts/geom"
+
+// =========== Path Mode ==========
+
+type PathMode uint8
+
+const (
+	PathModeLoop PathMode = iota
+	PathModePingPong
+	PathModeOnce
+)
+
+func (pm PathMode) String() string {
+	switch pm {
+	case PathModeLoop:
+		return "Loop"
+	case PathModePingPong:
+		return "PingPong"
+	case PathModeOnce:
+		return "Once"
+	default:
+		return "Unknown"
+	}
+}
+
+func (pm PathMode) IsValid() bool {
+	return pm <= PathModeOnce
+}
+
+// PathModeByName returns the path mode matching the given name.
+func PathModeByName(name string) (PathMode, bool) {
+	for pm := PathModeLoop; pm.IsValid(); pm++ {
+		if pm.String() == name {
+			return pm, true
+		}
+	}
+	return PathModeLoop, false
+}
+
+// =========== Path ==========
+
+// Path drives a kinematic collider through a series of waypoints at a constant speed.
+// Waypoints are world space positions for the collider's position.
+type Path struct {
+	Points [][2]float32
+	Speed  float32
+	Mode   PathMode
+
+	target    int
+	direction int
+	finished  bool
+}
+
+func NewPath(points [][2]float32, speed float32, mode PathMode) *Path {
+	return &Path{
+		Points:    points,
+		Speed:     speed,
+		Mode:      mode,
+		direction: 1,
+	}
+}
+
+// Finished returns true once a PathModeOnce path has reached its last waypoint.
+func (p *Path) Finished() bool {
+	return p.finished
+}
+
+// Reset restarts the path from its first waypoint.
+func (p *Path) Reset() {
+	p.target = 0
+	p.direction = 1
+	p.finished = false
+}
+
+// velocity returns the velocity required to advance along the path from (x, y) during dt.
+func (p *Path) velocity(x, y, dt float32) (vx, vy float32) {
+	if p.finished || len(p.Points) == 0 || dt <= 0 {
+		return 0, 0
+	}
+
+	travel := p.Speed * dt
+	posX, posY := x, y
+
+	// Consume waypoints until the travel distance for this step is used up
+	for i := 0; i <= len(p.Points) && travel > 0 && !p.finished; i++ {
+		target := p.Points[p.target]
+		dist := geom.Distance([2]float32{posX, posY}, target)
+
+		if dist > travel {
+			posX += (target[0] - posX) / dist * travel
+			posY += (target[1] - posY) / dist * travel
+			break
+		}
+
+		posX, posY = target[0], target[1]
+		travel -= dist
+		p.advance()
+	}
+
+	return (posX - x) / dt, (posY - y) / dt
+}
+
+func (p *Path) advance() {
+	last := len(p.Points) - 1
+
+	switch p.Mode {
+	case PathModeLoop:
+		p.target = (p.target + 1) % len(p.Points)
+	case PathModePingPong:
+		if p.target+p.direction > last || p.target+p.direction < 0 {
+			p.direction = -p.direction
+		}
+		p.target = min(max(p.target+p.direction, 0), last)
+	default:
+		if p.target == last {
+			p.finished = true
+			return
+		}
+		p.target++
+	}
+}
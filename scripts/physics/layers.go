@@ -1,37 +1,46 @@
 package physics
 
-var collisionMatrix [32][32]bool
+// collisionMasks holds, for each layer, a bit per layer it collides with.
+var collisionMasks [MaxCollisionLayers]uint32
 
 func init() {
-	for i := range 32 {
-		collisionMatrix[CollisionLayerDefault][i] = true
-		collisionMatrix[i][CollisionLayerDefault] = true
-	}
+	ResetLayers()
 }
 
 // EnableCollision enables collision detection between the two specified layers.
 func EnableCollision(layerA, layerB Layer) {
-	collisionMatrix[layerA][layerB] = true
-	collisionMatrix[layerB][layerA] = true
+	collisionMasks[layerA] |= layerB.Bit()
+	collisionMasks[layerB] |= layerA.Bit()
 }
 
 // DisableCollision disables collision detection between the two specified layers.
 func DisableCollision(layerA, layerB Layer) {
-	collisionMatrix[layerA][layerB] = false
-	collisionMatrix[layerB][layerA] = false
+	collisionMasks[layerA] &^= layerB.Bit()
+	collisionMasks[layerB] &^= layerA.Bit()
+}
+
+// ClearCollisionMatrix disables collision detection between all layers, including Default.
+func ClearCollisionMatrix() {
+	collisionMasks = [MaxCollisionLayers]uint32{}
 }
 
 // ShouldCollide returns true if collision detection is enabled between the two specified layers.
 func ShouldCollide(layerA, layerB Layer) bool {
-	return collisionMatrix[layerA][layerB]
+	return collisionMasks[layerA]&layerB.Bit() != 0
+}
+
+// CollisionMask returns the layers the specified layer collides with, one bit per layer.
+// A collider on layer A can be tested against a set of layers with a single AND.
+func CollisionMask(layer Layer) uint32 {
+	return collisionMasks[layer]
 }
 
 // Layer represents a collision layer.
 // Layers are used to categorize colliders and manage their interactions.
 //
-// Layers are defined at runtime using NewLayer.
-// The first layer created is assigned the value 0, the second layer 1, and so on.
-// A maximum of 255 layers can be created.
+// Layers are defined at runtime using NewLayer, usually from the layers declared in the Tiled project.
+// The Default layer is always 0, the first layer created is assigned the value 1, and so on.
+// A maximum of MaxCollisionLayers layers can exist, so that a layer's collision mask fits a uint32.
 //
 // Example usage:
 //
@@ -45,13 +54,24 @@ type Layer uint8
 const (
 	MaxCollisionLayers = 32
 
-	CollisionLayerDefault Layer = iota // Default collision layer
+	CollisionLayerDefault Layer = 0 // Default collision layer
 )
 
 var nameByLayer = map[Layer]string{
 	CollisionLayerDefault: "Default",
 }
 
+// ResetLayers removes every layer except Default, which collides with all layers.
+func ResetLayers() {
+	clear(nameByLayer)
+	nameByLayer[CollisionLayerDefault] = "Default"
+
+	ClearCollisionMatrix()
+	for i := range MaxCollisionLayers {
+		EnableCollision(CollisionLayerDefault, Layer(i))
+	}
+}
+
 // NewLayer creates a new collision layer with the given name.
 // It panics if the maximum number of layers MaxCollisionLayers is exceeded.
 func NewLayer(name string) Layer {
@@ -77,7 +97,22 @@ func (l Layer) IsValid() bool {
 	return ok
 }
 
+// Bit returns the layer's bit within a collision mask.
+func (l Layer) Bit() uint32 {
+	return 1 << l
+}
+
 func NameByLayer(layer Layer) (string, bool) {
 	name, ok := nameByLayer[layer]
 	return name, ok
 }
+
+// LayerByName returns the layer created with the given name.
+func LayerByName(name string) (Layer, bool) {
+	for layer, layerName := range nameByLayer {
+		if layerName == name {
+			return layer, true
+		}
+	}
+	return CollisionLayerDefault, false
+}
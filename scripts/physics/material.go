@@ -0,0 +1,40 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/adm87/deepdown/scripts/geom"
+)
+
+// Material describes how a collider's surface responds to contact.
+type Material struct {
+	Friction    float32 // Fraction of horizontal velocity removed each step while grounded, from 0 to 1
+	Restitution float32 // Fraction of velocity kept when bouncing off a surface, from 0 to 1
+}
+
+// DefaultMaterial returns the material assigned to new colliders.
+func DefaultMaterial() Material {
+	return Material{
+		Friction:    0.25,
+		Restitution: 0.0,
+	}
+}
+
+// combineFriction returns the friction between two materials.
+func combineFriction(a, b Material) float32 {
+	friction := float32(math.Sqrt(float64(max(a.Friction, 0) * max(b.Friction, 0))))
+	return min(friction, 1)
+}
+
+// combineRestitution returns the restitution between two materials.
+func combineRestitution(a, b Material) float32 {
+	return clamp(max(a.Restitution, b.Restitution), 0, 1)
+}
+
+// bounce returns the velocity after hitting a surface, or zero if the impact is too slow to bounce.
+func bounce(velocity, restitution float32) float32 {
+	if restitution <= 0 || float64(geom.Abs(velocity)) < MinimumBounceVelocity {
+		return 0
+	}
+	return -velocity * restitution
+}
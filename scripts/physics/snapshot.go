@@ -0,0 +1,232 @@
+package physics
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Snapshot captures the simulation state of the dynamic, trigger and kinematic colliders in a world.
+// Static colliders and volumes never change and are not captured. Colliders are identified by ID,
+// so a snapshot can only be restored into the world it was taken from.
+type Snapshot struct {
+	Steps      uint64              // Fixed steps the world had taken, used to interpolate restored colliders
+	Bodies     []BodySnapshot      // Ordered by collider ID
+	Kinematics []KinematicSnapshot // Ordered by collider ID
+}
+
+// BodySnapshot is the simulation state of a single dynamic or trigger collider.
+type BodySnapshot struct {
+	ID           uint32
+	Position     [2]float32
+	PrevPosition [2]float32
+	PrevStep     uint64 // Step in which PrevPosition was recorded
+	NextPosition [2]float32
+	Velocity     [2]float32
+	Force        [2]float32 // Force accumulated for the next step
+	Impulse      [2]float32 // Impulse accumulated for the next step
+
+	Ground              uint32 // ID of the ground collider, 0 when airborne
+	OnGround            bool
+	OnOneWay            bool
+	TimeSinceLeftGround float32
+	DropThroughTime     float32
+	Sleeping            bool
+	RestSteps           int32
+	Climbing            bool
+}
+
+// KinematicSnapshot is the simulation state of a single kinematic collider and its path.
+type KinematicSnapshot struct {
+	ID           uint32
+	Position     [2]float32
+	PrevPosition [2]float32
+	PrevStep     uint64 // Step in which PrevPosition was recorded
+	Velocity     [2]float32
+
+	PathTarget    int32
+	PathDirection int32
+	PathFinished  bool
+}
+
+// Snapshot captures the current state of every non-static collider in the world.
+func (w *World) Snapshot() *Snapshot {
+	snapshot := &Snapshot{Steps: w.steps}
+
+	for _, collider := range w.colliders {
+		info := collider.Info()
+		x, y := collider.Position()
+
+		if info.IsVolume() {
+			continue
+		}
+
+		switch info.State {
+		case ColliderStateStatic:
+			continue
+
+		case ColliderStateKinematic:
+			ks := KinematicSnapshot{
+				ID:           info.id,
+				Position:     [2]float32{x, y},
+				PrevPosition: info.prevPosition,
+				PrevStep:     info.prevStep,
+				Velocity:     info.Velocity,
+			}
+			if info.Path != nil {
+				ks.PathTarget = int32(info.Path.target)
+				ks.PathDirection = int32(info.Path.direction)
+				ks.PathFinished = info.Path.finished
+			}
+			snapshot.Kinematics = append(snapshot.Kinematics, ks)
+
+		default:
+			bs := BodySnapshot{
+				ID:                  info.id,
+				Position:            [2]float32{x, y},
+				PrevPosition:        info.prevPosition,
+				PrevStep:            info.prevStep,
+				NextPosition:        info.nextPosition,
+				Velocity:            info.Velocity,
+				Force:               info.force,
+				Impulse:             info.impulse,
+				OnGround:            info.OnGround,
+				OnOneWay:            info.onOneWay,
+				TimeSinceLeftGround: info.timeSinceLeftGround,
+				DropThroughTime:     info.dropThroughTime,
+				Sleeping:            info.sleeping,
+				RestSteps:           info.restSteps,
+				Climbing:            info.Climbing,
+			}
+			if info.ground != nil {
+				bs.Ground = info.ground.Info().id
+			}
+			snapshot.Bodies = append(snapshot.Bodies, bs)
+		}
+	}
+
+	// Map iteration order is random, keep snapshots comparable
+	slices.SortFunc(snapshot.Bodies, func(a, b BodySnapshot) int { return cmp.Compare(a.ID, b.ID) })
+	slices.SortFunc(snapshot.Kinematics, func(a, b KinematicSnapshot) int { return cmp.Compare(a.ID, b.ID) })
+
+	return snapshot
+}
+
+// Restore returns the world to the state captured by a snapshot.
+// Colliders added since the snapshot was taken keep their current state.
+// An error is returned if the snapshot references a collider that is no longer in the world.
+func (w *World) Restore(snapshot *Snapshot) error {
+	w.steps = snapshot.Steps
+
+	for i := range snapshot.Bodies {
+		bs := &snapshot.Bodies[i]
+
+		collider, ok := w.colliders[bs.ID]
+		if !ok {
+			return fmt.Errorf("snapshot body %d not found in world", bs.ID)
+		}
+
+		// SetPosition also moves the next position, so the captured one is applied after it
+		collider.SetPosition(bs.Position[0], bs.Position[1])
+
+		info := collider.Info()
+		info.prevPosition = bs.PrevPosition
+		info.prevStep = bs.PrevStep
+		info.nextPosition = bs.NextPosition
+		info.Velocity = bs.Velocity
+		info.force = bs.Force
+		info.impulse = bs.Impulse
+		info.OnGround = bs.OnGround
+		info.onOneWay = bs.OnOneWay
+		info.timeSinceLeftGround = bs.TimeSinceLeftGround
+		info.dropThroughTime = bs.DropThroughTime
+		info.sleeping = bs.Sleeping
+		info.restSteps = bs.RestSteps
+		info.Climbing = bs.Climbing
+		info.ground = nil
+		if bs.Ground != 0 {
+			if info.ground, ok = w.colliders[bs.Ground]; !ok {
+				return fmt.Errorf("snapshot ground %d not found in world", bs.Ground)
+			}
+		}
+
+		w.bodyPhase.Update(collider)
+	}
+
+	for i := range snapshot.Kinematics {
+		ks := &snapshot.Kinematics[i]
+
+		collider, ok := w.colliders[ks.ID]
+		if !ok {
+			return fmt.Errorf("snapshot kinematic %d not found in world", ks.ID)
+		}
+
+		collider.SetPosition(ks.Position[0], ks.Position[1])
+
+		info := collider.Info()
+		info.prevPosition = ks.PrevPosition
+		info.prevStep = ks.PrevStep
+		info.Velocity = ks.Velocity
+		if info.Path != nil {
+			info.Path.target = int(ks.PathTarget)
+			info.Path.direction = int(ks.PathDirection)
+			info.Path.finished = ks.PathFinished
+		}
+
+		w.kinematicPhase.Update(collider)
+	}
+
+	return nil
+}
+
+// MarshalBinary encodes the snapshot in a compact little-endian binary form.
+func (s *Snapshot) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := binary.Write(&buf, binary.LittleEndian, s.Steps); err != nil {
+		return nil, err
+	}
+	counts := [2]uint32{uint32(len(s.Bodies)), uint32(len(s.Kinematics))}
+	if err := binary.Write(&buf, binary.LittleEndian, counts); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, s.Bodies); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, s.Kinematics); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a snapshot encoded by MarshalBinary.
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	reader := bytes.NewReader(data)
+
+	if err := binary.Read(reader, binary.LittleEndian, &s.Steps); err != nil {
+		return err
+	}
+
+	var counts [2]uint32
+	if err := binary.Read(reader, binary.LittleEndian, &counts); err != nil {
+		return err
+	}
+
+	// Guard against corrupt counts before allocating
+	size := uint64(counts[0])*uint64(binary.Size(BodySnapshot{})) + uint64(counts[1])*uint64(binary.Size(KinematicSnapshot{}))
+	if size != uint64(reader.Len()) {
+		return errors.New("invalid snapshot size")
+	}
+
+	s.Bodies = make([]BodySnapshot, counts[0])
+	s.Kinematics = make([]KinematicSnapshot, counts[1])
+
+	if err := binary.Read(reader, binary.LittleEndian, s.Bodies); err != nil {
+		return err
+	}
+	return binary.Read(reader, binary.LittleEndian, s.Kinematics)
+}
@@ -0,0 +1,142 @@
+package physics
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/adm87/deepdown/scripts/deepdown"
+)
+
+// newSnapshotScene returns a world with resting, falling, sliding and carried bodies, a character and a moving platform.
+func newSnapshotScene(broadphase BroadphaseType) (*World, []Collider) {
+	config := DefaultConfig()
+	config.Broadphase = broadphase
+	w := NewWorld(deepdown.NewContext(), config)
+
+	floor := CollisionRoleFloor | CollisionRoleWall
+	addSolid(w, w.Pool().GetBoxCollider(0, 200, 400, 16), floor)
+	addSolid(w, w.Pool().GetTriangleCollider(100, 160, [6]float32{0, 40, 40, 0, 40, 40}), floor)
+
+	platform := w.Pool().GetBoxCollider(200, 150, 40, 8)
+	platform.State = ColliderStateKinematic
+	platform.Velocity = [2]float32{20, -5}
+	addSolid(w, platform, floor)
+
+	bodies := []Collider{platform}
+	for _, p := range [][2]float32{{10, 192}, {30, 40}, {120, 140}, {210, 142}, {300, 100}} {
+		body := addBody(w, p[0], p[1], 8, 8)
+		body.Velocity = [2]float32{15, 0}
+		bodies = append(bodies, body)
+	}
+
+	cc := addCharacter(w, 60, 150, 4, 8)
+	cc.collider.Info().Velocity = [2]float32{-25, 0}
+	bodies = append(bodies, cc.collider)
+
+	return w, bodies
+}
+
+func TestSnapshotRestoreIsDeterministic(t *testing.T) {
+	tests := []struct {
+		name       string
+		broadphase BroadphaseType
+		restore    func(t *testing.T, s *Snapshot) *Snapshot
+	}{
+		{name: "grid", broadphase: BroadphaseGrid, restore: func(t *testing.T, s *Snapshot) *Snapshot { return s }},
+		{name: "aabb tree", broadphase: BroadphaseAABBTree, restore: func(t *testing.T, s *Snapshot) *Snapshot { return s }},
+		{name: "sweep and prune", broadphase: BroadphaseSweepAndPrune, restore: func(t *testing.T, s *Snapshot) *Snapshot { return s }},
+		{name: "binary round trip", broadphase: BroadphaseGrid, restore: func(t *testing.T, s *Snapshot) *Snapshot {
+			data, err := s.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := &Snapshot{}
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			return decoded
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, bodies := newSnapshotScene(tt.broadphase)
+			for range 20 {
+				stepWorld(w)
+			}
+
+			snapshot := w.Snapshot()
+			before, err := snapshot.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			render := renderPositions(w, bodies)
+
+			// Record the steps taken from the snapshot, then replay them from the restored state
+			var want [][]byte
+			for range 40 {
+				stepWorld(w)
+				want = append(want, mustMarshal(t, w.Snapshot()))
+			}
+
+			if err := w.Restore(tt.restore(t, snapshot)); err != nil {
+				t.Fatal(err)
+			}
+			if got := mustMarshal(t, w.Snapshot()); !bytes.Equal(got, before) {
+				t.Fatal("restored state differs from the snapshot")
+			}
+			if got := renderPositions(w, bodies); !slices.Equal(got, render) {
+				t.Fatalf("render positions after restore = %v, want %v", got, render)
+			}
+
+			for step := range want {
+				stepWorld(w)
+				if got := mustMarshal(t, w.Snapshot()); !bytes.Equal(got, want[step]) {
+					t.Fatalf("step %d after restore differs from the recorded step", step)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotRejectsCorruptData(t *testing.T) {
+	w, _ := newSnapshotScene(BroadphaseGrid)
+	stepWorld(w)
+	data := mustMarshal(t, w.Snapshot())
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "truncated", data: data[:len(data)-1]},
+		{name: "trailing bytes", data: append(bytes.Clone(data), 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (&Snapshot{}).UnmarshalBinary(tt.data); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func renderPositions(w *World, bodies []Collider) [][2]float32 {
+	positions := make([][2]float32, 0, len(bodies))
+	for _, body := range bodies {
+		x, y := w.RenderPosition(body, 0.5)
+		positions = append(positions, [2]float32{x, y})
+	}
+	return positions
+}
+
+func mustMarshal(t *testing.T, s *Snapshot) []byte {
+	t.Helper()
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
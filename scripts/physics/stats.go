@@ -0,0 +1,156 @@
+package physics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// =========== Step Phase ==========
+
+// StepPhase is a stage of World.Update timed by the step statistics.
+type StepPhase uint8
+
+const (
+	StepPhaseForces      StepPhase = iota // Spring forces
+	StepPhaseKinematics                   // Kinematic movement and pushing
+	StepPhaseCharacters                   // Character controller movement
+	StepPhaseBroadphase                   // Gathering the active bodies
+	StepPhaseIntegration                  // Forces, gravity, volumes and ground checks
+	StepPhaseCollisions                   // Narrowphase tests and static resolution
+	StepPhaseConstraints                  // Distance and rope constraint solving
+	StepPhaseCommit                       // Moving bodies to their resolved positions
+	StepPhaseCount
+)
+
+func (sp StepPhase) String() string {
+	switch sp {
+	case StepPhaseForces:
+		return "Forces"
+	case StepPhaseKinematics:
+		return "Kinematics"
+	case StepPhaseCharacters:
+		return "Characters"
+	case StepPhaseBroadphase:
+		return "Broadphase"
+	case StepPhaseIntegration:
+		return "Integration"
+	case StepPhaseCollisions:
+		return "Collisions"
+	case StepPhaseConstraints:
+		return "Constraints"
+	case StepPhaseCommit:
+		return "Commit"
+	default:
+		return "Unknown"
+	}
+}
+
+func (sp StepPhase) IsValid() bool {
+	return sp < StepPhaseCount
+}
+
+// =========== Step Stats ==========
+
+// StepStats describes the work done by a single World.Update.
+type StepStats struct {
+	ActiveBodies         int `json:"active_bodies"`         // Dynamic bodies simulated
+	Characters           int `json:"characters"`            // Character controllers moved
+	CandidatePairs       int `json:"candidate_pairs"`       // Colliders returned by broadphase queries for solids
+	NarrowphaseTests     int `json:"narrowphase_tests"`     // Overlap tests run on candidates
+	Contacts             int `json:"contacts"`              // Overlap tests that found a contact
+	ResolutionIterations int `json:"resolution_iterations"` // Contact resolution passes over bodies and characters
+
+	Phases [StepPhaseCount]time.Duration `json:"phases"` // Time spent in each phase
+	Total  time.Duration                 `json:"total"`  // Time spent in the whole step
+}
+
+// Phase returns the time spent in a phase of the step.
+func (s StepStats) Phase(phase StepPhase) time.Duration {
+	if !phase.IsValid() {
+		return 0
+	}
+	return s.Phases[phase]
+}
+
+// String formats the statistics as short lines for a debug overlay.
+func (s StepStats) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Bodies: %d Chars: %d\n", s.ActiveBodies, s.Characters)
+	fmt.Fprintf(&sb, "Pairs: %d Tests: %d\n", s.CandidatePairs, s.NarrowphaseTests)
+	fmt.Fprintf(&sb, "Contacts: %d Iters: %d\n", s.Contacts, s.ResolutionIterations)
+	fmt.Fprintf(&sb, "Step: %s", s.Total.Round(time.Microsecond))
+	for phase := range StepPhaseCount {
+		if elapsed := s.Phases[phase].Round(time.Microsecond); elapsed > 0 {
+			fmt.Fprintf(&sb, "\n %s: %s", phase, elapsed)
+		}
+	}
+	return sb.String()
+}
+
+// SetCollectStats enables or disables collecting statistics for every step.
+func (w *World) SetCollectStats(enabled bool) {
+	if w.collectStats && !enabled {
+		w.statsMu.Lock()
+		w.stats = StepStats{}
+		w.statsMu.Unlock()
+	}
+	w.collectStats = enabled
+}
+
+// CollectsStats returns true if the world collects statistics for every step.
+func (w *World) CollectsStats() bool {
+	return w.collectStats
+}
+
+// Stats returns the statistics of the last step collected, or zero values if collection is disabled.
+// It is safe to call from other goroutines, such as a metrics endpoint.
+func (w *World) Stats() StepStats {
+	w.statsMu.Lock()
+	defer w.statsMu.Unlock()
+	return w.stats
+}
+
+// beginStats resets the step statistics before a step.
+func (w *World) beginStats() {
+	if !w.collectStats {
+		return
+	}
+	w.step = StepStats{}
+	w.stepStart = time.Now()
+	w.phaseStart = w.stepStart
+}
+
+// endPhase records the time spent in a phase since the previous phase ended.
+func (w *World) endPhase(phase StepPhase) {
+	if !w.collectStats {
+		return
+	}
+	now := time.Now()
+	w.step.Phases[phase] += now.Sub(w.phaseStart)
+	w.phaseStart = now
+}
+
+// endStats publishes the statistics of the step that just finished.
+func (w *World) endStats() {
+	if !w.collectStats {
+		return
+	}
+	w.step.Total = time.Since(w.stepStart)
+
+	w.statsMu.Lock()
+	w.stats = w.step
+	w.statsMu.Unlock()
+}
+
+// checkOverlap tests two colliders for overlap, counting the test and any contact in the step statistics.
+func (w *World) checkOverlap(a, b Collider) (Collision, bool) {
+	contact, overlaps := CheckOverlap(a, b)
+	if w.collectStats {
+		w.step.NarrowphaseTests++
+		if overlaps {
+			w.step.Contacts++
+		}
+	}
+	return contact, overlaps
+}
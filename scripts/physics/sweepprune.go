@@ -0,0 +1,122 @@
+package physics
+
+import "sort"
+
+type sapEntry struct {
+	minX, minY, maxX, maxY float32
+	collider               Collider
+}
+
+// SweepAndPrune keeps colliders sorted by their minimum X so queries only scan a narrow band.
+// Bodies move a little each step, so updates usually only swap a few neighbouring entries.
+type SweepAndPrune struct {
+	entries  []sapEntry           // Sorted by minX
+	keys     map[Collider]float32 // Stored minX of each collider, used to find its entry
+	maxWidth float32              // Widest stored collider, bounds how far back a query starts
+
+	results []Collider // Reusable query results
+}
+
+func NewSweepAndPrune() *SweepAndPrune {
+	return &SweepAndPrune{
+		keys: make(map[Collider]float32),
+	}
+}
+
+func (sap *SweepAndPrune) Insert(collider Collider) {
+	if _, exists := sap.keys[collider]; exists {
+		return
+	}
+
+	minX, minY, maxX, maxY := collider.AABB()
+	index := sort.Search(len(sap.entries), func(i int) bool {
+		return sap.entries[i].minX >= minX
+	})
+
+	sap.entries = append(sap.entries, sapEntry{})
+	copy(sap.entries[index+1:], sap.entries[index:])
+	sap.entries[index] = sapEntry{minX, minY, maxX, maxY, collider}
+
+	sap.keys[collider] = minX
+	sap.maxWidth = max(sap.maxWidth, maxX-minX)
+}
+
+func (sap *SweepAndPrune) Remove(collider Collider) {
+	index := sap.find(collider)
+	if index < 0 {
+		return
+	}
+
+	width := sap.entries[index].maxX - sap.entries[index].minX
+
+	sap.entries = append(sap.entries[:index], sap.entries[index+1:]...)
+	delete(sap.keys, collider)
+
+	// Shrink the query band if the widest collider was removed
+	if width >= sap.maxWidth {
+		sap.maxWidth = 0
+		for i := range sap.entries {
+			sap.maxWidth = max(sap.maxWidth, sap.entries[i].maxX-sap.entries[i].minX)
+		}
+	}
+}
+
+func (sap *SweepAndPrune) Update(collider Collider) {
+	index := sap.find(collider)
+	if index < 0 {
+		sap.Insert(collider)
+		return
+	}
+
+	minX, minY, maxX, maxY := collider.AABB()
+	sap.entries[index] = sapEntry{minX, minY, maxX, maxY, collider}
+	sap.keys[collider] = minX
+	sap.maxWidth = max(sap.maxWidth, maxX-minX)
+
+	// Restore order by swapping with neighbours
+	for index > 0 && sap.entries[index-1].minX > minX {
+		sap.entries[index-1], sap.entries[index] = sap.entries[index], sap.entries[index-1]
+		index--
+	}
+	for index < len(sap.entries)-1 && sap.entries[index+1].minX < minX {
+		sap.entries[index+1], sap.entries[index] = sap.entries[index], sap.entries[index+1]
+		index++
+	}
+}
+
+// Query returns the colliders overlapping the bounds.
+// The returned slice is reused between queries.
+func (sap *SweepAndPrune) Query(minX, minY, maxX, maxY float32) []Collider {
+	sap.results = sap.results[:0]
+
+	start := sort.Search(len(sap.entries), func(i int) bool {
+		return sap.entries[i].minX >= minX-sap.maxWidth
+	})
+
+	for i := start; i < len(sap.entries) && sap.entries[i].minX <= maxX; i++ {
+		e := &sap.entries[i]
+		if overlapsAABB(e.minX, e.minY, e.maxX, e.maxY, minX, minY, maxX, maxY) {
+			sap.results = append(sap.results, e.collider)
+		}
+	}
+
+	return sap.results
+}
+
+// find returns the index of a collider's entry, or -1 if it is not stored.
+func (sap *SweepAndPrune) find(collider Collider) int {
+	key, exists := sap.keys[collider]
+	if !exists {
+		return -1
+	}
+
+	index := sort.Search(len(sap.entries), func(i int) bool {
+		return sap.entries[i].minX >= key
+	})
+	for ; index < len(sap.entries) && sap.entries[index].minX == key; index++ {
+		if sap.entries[index].collider == collider {
+			return index
+		}
+	}
+	return -1
+}
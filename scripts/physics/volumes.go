@@ -0,0 +1,88 @@
+package physics
+
+// Volumes returns the roles of the volumes overlapping the collider as of the last step.
+func (ci *ColliderInfo) Volumes() Role {
+	return ci.volumes
+}
+
+// InWater returns true if the collider overlaps a water volume.
+func (ci *ColliderInfo) InWater() bool {
+	return ci.volumes&CollisionRoleWater != 0
+}
+
+// Submerged returns the fraction of the collider's height below the water surface, from 0 to 1.
+func (ci *ColliderInfo) Submerged() float32 {
+	return ci.submerged
+}
+
+// WaterSurface returns the height of the water surface the collider is in, or false when it is not in water.
+func (ci *ColliderInfo) WaterSurface() (float32, bool) {
+	return ci.waterSurface, ci.InWater()
+}
+
+// AtWaterSurface returns true if the collider is partially submerged, breaking the water surface.
+func (ci *ColliderInfo) AtWaterSurface() bool {
+	return ci.InWater() && ci.submerged < 1
+}
+
+// OnClimbable returns true if the collider overlaps a climbable volume.
+func (ci *ColliderInfo) OnClimbable() bool {
+	return ci.volumes&CollisionRoleClimbable != 0
+}
+
+// updateVolumes records the volumes overlapping a collider and how deep it is in water.
+// Climbing stops once the collider leaves every climbable volume.
+func (w *World) updateVolumes(collider Collider, info *ColliderInfo) {
+	info.volumes = CollisionRoleNone
+	info.submerged = 0
+
+	minX, minY, maxX, maxY := collider.AABB()
+
+	volumes := w.volumePhase.Query(minX, minY, maxX, maxY)
+	for i := range volumes {
+		volumeInfo := volumes[i].Info()
+		if !ShouldCollide(info.Layer, volumeInfo.Layer) {
+			continue
+		}
+		if _, overlaps := w.checkOverlap(collider, volumes[i]); !overlaps {
+			continue
+		}
+
+		if volumeInfo.Role&CollisionRoleWater != 0 {
+			_, surface, _, _ := volumes[i].AABB()
+			if !info.InWater() || surface < info.waterSurface {
+				info.waterSurface = surface
+			}
+		}
+		info.volumes |= volumeInfo.Role & CollisionRoleVolume
+	}
+
+	if info.InWater() && maxY > minY {
+		info.submerged = clamp((maxY-max(info.waterSurface, minY))/(maxY-minY), 0, 1)
+	}
+
+	if !info.OnClimbable() {
+		info.Climbing = false
+	}
+}
+
+// verticalAcceleration returns the gravity applied to a body, adjusted by the volumes it is in.
+func (w *World) verticalAcceleration(info *ColliderInfo) float32 {
+	gravity := info.Body.gravity(&w.config)
+	switch {
+	case info.Climbing:
+		return 0
+	case info.InWater():
+		return gravity*w.config.WaterGravityScale - w.config.WaterBuoyancy*info.submerged
+	default:
+		return gravity
+	}
+}
+
+// applyWaterDrag slows a body moving through water.
+func (w *World) applyWaterDrag(info *ColliderInfo) {
+	if info.InWater() {
+		info.Velocity[0] *= w.config.WaterDrag
+		info.Velocity[1] *= w.config.WaterDrag
+	}
+}
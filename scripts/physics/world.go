@@ -1,26 +1,24 @@
 package physics
 
 import (
+	"cmp"
+	"fmt"
 	"math"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/adm87/deepdown/scripts/deepdown"
 	"github.com/adm87/deepdown/scripts/geom"
-	"github.com/adm87/utilities/hash"
 )
 
 const (
-	GridCellSize float32 = 8.0
-	Gravity      float32 = 400.0
-	Epsilon      float32 = 0.0001
+	Epsilon float32 = 0.0001
 
 	MinimumVelocityThreshold float64 = 0.01
-	MaxVelocityRiseSpeed     float32 = -150.0
-	MaxVelocityFallSpeed     float32 = 200.0
+	MinimumBounceVelocity    float64 = 20.0
 
-	GroundCheckDistance  float32 = 1.0
-	GroundCheckTolerance float32 = 0.5
-
-	VelocityDamping float32 = 0.75
+	OneWayDropThroughTime float32 = 0.15
 )
 
 func clamp[T float32 | float64](value, min, max T) T {
@@ -43,85 +41,272 @@ func DecodePair(pair CollisionPair) (id1, id2 uint32) {
 }
 
 type World struct {
-	ctx deepdown.Context
-
-	staticGrid *hash.Grid[Collider] // Static world colliders
-	bodyGrid   *hash.Grid[Collider] // Dynamic and trigger body colliders
+	ctx    deepdown.Context
+	config Config
+
+	staticPhase    Broadphase // Static world colliders
+	kinematicPhase Broadphase // Kinematic colliders moved by paths or code
+	bodyPhase      Broadphase // Dynamic, trigger and character body colliders
+	volumePhase    Broadphase // Water and climbable volumes
+
+	pool            *ColliderPool          // Allocates the world's colliders
+	colliders       map[uint32]Collider    // All colliders in the world by ID
+	kinematics      []Collider             // Kinematic colliders updated every fixed step
+	alwaysSimulated []Collider             // Bodies simulated regardless of the update region
+	characters      []*CharacterController // Character controllers moved every fixed step
+	constraints     []*Constraint          // Constraints solved every fixed step
+	active          []Collider             // Reusable buffer for the bodies simulated each step
+	solids          []Collider             // Reusable buffer for static and kinematic queries
+
+	steps uint64 // Fixed steps taken
+
+	collectStats bool       // Collect statistics for every step
+	step         StepStats  // Statistics of the step in progress
+	stepStart    time.Time  // Start of the step in progress
+	phaseStart   time.Time  // Start of the phase in progress
+	stats        StepStats  // Statistics of the last finished step
+	statsMu      sync.Mutex // Guards stats for readers on other goroutines
 }
 
-func NewWorld(ctx deepdown.Context) *World {
+func NewWorld(ctx deepdown.Context, config Config) *World {
 	return &World{
-		ctx:        ctx,
-		staticGrid: hash.NewGrid[Collider](GridCellSize, GridCellSize),
-		bodyGrid:   hash.NewGrid[Collider](GridCellSize, GridCellSize),
+		ctx:            ctx,
+		config:         config,
+		staticPhase:    NewBroadphase(config),
+		kinematicPhase: NewBroadphase(config),
+		bodyPhase:      NewBroadphase(config),
+		volumePhase:    NewBroadphase(config),
+		pool:           NewColliderPool(config.DebugPools),
+		colliders:      make(map[uint32]Collider),
 	}
 }
 
+// Config returns the physics parameters of the world.
+func (w *World) Config() Config {
+	return w.config
+}
+
+// Pool returns the pool allocating the world's colliders.
+// Colliders added to the world must come from its pool, since IDs are only unique within a pool.
+func (w *World) Pool() *ColliderPool {
+	return w.pool
+}
+
+// AlwaysSimulated returns the bodies simulated regardless of the update region.
+func (w *World) AlwaysSimulated() []Collider {
+	return w.alwaysSimulated
+}
+
 func (w *World) AddCollider(collider Collider) {
+	if w.config.DebugPools {
+		info := collider.Info()
+		if info.released {
+			panic(fmt.Sprintf("collider %d added after being released", info.id))
+		}
+		if info.pool != w.pool {
+			panic(fmt.Sprintf("collider %d added to a world that did not allocate it", info.id))
+		}
+	}
+
+	w.colliders[collider.Info().id] = collider
+
+	// Volumes are static and never collide, whatever their state
+	if collider.Info().IsVolume() {
+		w.volumePhase.Insert(collider)
+		return
+	}
+
 	switch collider.Info().State {
 	case ColliderStateStatic:
-		w.insert(collider, w.staticGrid)
+		w.staticPhase.Insert(collider)
+	case ColliderStateKinematic:
+		w.kinematicPhase.Insert(collider)
+		w.kinematics = append(w.kinematics, collider)
 	default:
-		w.insert(collider, w.bodyGrid)
+		w.bodyPhase.Insert(collider)
+		if collider.Info().Body.AlwaysSimulate {
+			w.alwaysSimulated = append(w.alwaysSimulated, collider)
+		}
 	}
 }
 
+// Reset removes every collider, character and constraint from the world and applies a new config.
+// The removed colliders are released to the world's pool, which is kept so the next level recycles them.
+// Colliders and character controllers held outside the world must not be used after a reset.
+func (w *World) Reset(config Config) {
+	for _, collider := range w.colliders {
+		w.pool.Release(collider)
+	}
+	for _, cc := range w.characters {
+		cc.world = nil
+	}
+
+	w.config = config
+	w.pool.debug = config.DebugPools
+
+	w.staticPhase = NewBroadphase(config)
+	w.kinematicPhase = NewBroadphase(config)
+	w.bodyPhase = NewBroadphase(config)
+	w.volumePhase = NewBroadphase(config)
+
+	clear(w.colliders)
+	w.kinematics = nil
+	w.alwaysSimulated = nil
+	w.characters = nil
+	w.constraints = nil
+	w.active = nil
+	w.solids = nil
+}
+
+// RemoveCollider removes a collider from the world, along with the constraints and character controller attached to it.
 func (w *World) RemoveCollider(collider Collider) {
+	delete(w.colliders, collider.Info().id)
+
+	// Constraints cannot outlive the colliders they hold
+	w.constraints = slices.DeleteFunc(w.constraints, func(c *Constraint) bool {
+		return c.attachedTo(collider)
+	})
+
+	if collider.Info().IsVolume() {
+		w.volumePhase.Remove(collider)
+		w.wakeBodiesOn(collider)
+		return
+	}
+
 	switch collider.Info().State {
 	case ColliderStateStatic:
-		w.staticGrid.Remove(collider)
+		w.staticPhase.Remove(collider)
+		w.wakeBodiesOn(collider)
+	case ColliderStateKinematic:
+		w.kinematicPhase.Remove(collider)
+		w.kinematics = slices.DeleteFunc(w.kinematics, collider.Equals)
+		w.wakeBodiesOn(collider)
 	default:
-		w.bodyGrid.Remove(collider)
+		w.bodyPhase.Remove(collider)
+		w.alwaysSimulated = slices.DeleteFunc(w.alwaysSimulated, collider.Equals)
+
+		// Characters cannot outlive the colliders they move
+		w.characters = slices.DeleteFunc(w.characters, func(cc *CharacterController) bool {
+			if !cc.collider.Equals(collider) {
+				return false
+			}
+			cc.world = nil
+			return true
+		})
 	}
 }
 
 func (w *World) Update(dt float64, minX, minY, maxX, maxY float32) {
-	activeBodies := w.bodyGrid.Query(minX, minY, maxX, maxY)
+	w.steps++
+	w.beginStats()
+
+	w.applySpringForces(minX, minY, maxX, maxY)
+	w.endPhase(StepPhaseForces)
+
+	w.updateKinematics(dt)
+	w.endPhase(StepPhaseKinematics)
+
+	w.updateCharacters(dt)
+	w.endPhase(StepPhaseCharacters)
+
+	activeBodies := w.activeBodies(minX, minY, maxX, maxY)
+	w.endPhase(StepPhaseBroadphase)
 
 	w.preupdate(dt, activeBodies)
+	w.endPhase(StepPhaseIntegration)
 
 	w.handleCollisions(activeBodies)
+	w.endPhase(StepPhaseCollisions)
+
+	w.solveConstraints(activeBodies)
+	w.endPhase(StepPhaseConstraints)
 
 	w.postupdate(activeBodies)
+	w.endPhase(StepPhaseCommit)
+
+	w.step.ActiveBodies = len(activeBodies)
+	w.step.Characters = len(w.characters)
+	w.endStats()
+}
+
+// activeBodies returns the awake bodies within the simulation region around the given bounds,
+// along with every body that is always simulated. The returned slice is reused between calls.
+func (w *World) activeBodies(minX, minY, maxX, maxY float32) []Collider {
+	margin := w.config.SimulationMargin
+
+	w.active = append(w.active[:0], w.bodyPhase.Query(minX-margin, minY-margin, maxX+margin, maxY+margin)...)
+	w.active = append(w.active, w.alwaysSimulated...)
+	w.active = slices.CompactFunc(sortByID(w.active), func(a, b Collider) bool {
+		return a.Equals(b)
+	})
+
+	// Bodies given velocity while sleeping wake up, the rest are skipped.
+	// Characters are moved by their controllers instead.
+	w.active = slices.DeleteFunc(w.active, func(body Collider) bool {
+		info := body.Info()
+		if info.sleeping && (info.Velocity[0] != 0 || info.Velocity[1] != 0) {
+			info.Wake()
+		}
+		return info.sleeping || info.State == ColliderStateCharacter
+	})
+
+	return w.active
 }
 
 func (w *World) QueryStatic(minX, minY, maxX, maxY float32) []Collider {
-	return w.staticGrid.Query(minX, minY, maxX, maxY)
+	return w.staticPhase.Query(minX, minY, maxX, maxY)
+}
+
+func (w *World) QueryKinematic(minX, minY, maxX, maxY float32) []Collider {
+	return w.kinematicPhase.Query(minX, minY, maxX, maxY)
 }
 
 func (w *World) QueryBody(minX, minY, maxX, maxY float32) []Collider {
-	return w.bodyGrid.Query(minX, minY, maxX, maxY)
+	return w.bodyPhase.Query(minX, minY, maxX, maxY)
 }
 
-func (w *World) QueryStaticCells(minX, minY, maxX, maxY float32) []uint64 {
-	return w.staticGrid.QueryCells(minX, minY, maxX, maxY)
+func (w *World) QueryVolume(minX, minY, maxX, maxY float32) []Collider {
+	return w.volumePhase.Query(minX, minY, maxX, maxY)
 }
 
-func (w *World) QueryBodyCells(minX, minY, maxX, maxY float32) []uint64 {
-	return w.bodyGrid.QueryCells(minX, minY, maxX, maxY)
+// QueryStaticCells returns the occupied static grid cells, or nil when the world does not use the grid broadphase.
+func (w *World) QueryStaticCells(minX, minY, maxX, maxY float32) []uint64 {
+	if grid, ok := w.staticPhase.(*GridBroadphase); ok {
+		return grid.QueryCells(minX, minY, maxX, maxY)
+	}
+	return nil
 }
 
-func (w *World) insert(collider Collider, grid *hash.Grid[Collider]) {
-	minX, minY, maxX, maxY := collider.AABB()
-	switch c := collider.(type) {
-	// case *TriangleCollider:
-	// 	grid.InsertFunc(c, minX, minY, maxX, maxY, hash.NoGridPadding, func(cMinX, cMinY, cMaxX, cMaxY float32) bool {
-	// 		return c.Triangle.IntersectsAABB(cMinX, cMinY, cMaxX, cMaxY)
-	// 	})
-	default:
-		grid.Insert(c, minX, minY, maxX, maxY, hash.NoGridPadding)
+// QueryBodyCells returns the occupied body grid cells, or nil when the world does not use the grid broadphase.
+func (w *World) QueryBodyCells(minX, minY, maxX, maxY float32) []uint64 {
+	if grid, ok := w.bodyPhase.(*GridBroadphase); ok {
+		return grid.QueryCells(minX, minY, maxX, maxY)
 	}
+	return nil
 }
 
 func (w *World) preupdate(dt float64, activeBodies []Collider) {
 	for i := range activeBodies {
 		info := activeBodies[i].Info()
 
-		// Apply gravity and clamp vertical velocity
-		velY := clamp(info.Velocity[1]+Gravity*float32(dt), MaxVelocityRiseSpeed, MaxVelocityFallSpeed)
+		// Apply forces and impulses accumulated since the last step
+		info.integrateForces(float32(dt))
+
+		// Find the water and climbable volumes the body is in
+		w.updateVolumes(activeBodies[i], info)
+
+		// Apply gravity, adjusted by volumes, and clamp vertical velocity
+		riseSpeed, fallSpeed := info.Body.speedLimits(&w.config)
+		velY := clamp(info.Velocity[1]+w.verticalAcceleration(info)*float32(dt), riseSpeed, fallSpeed)
+
+		// Count down one-way platform drop through
+		if info.dropThroughTime > 0 {
+			info.dropThroughTime = max(info.dropThroughTime-float32(dt), 0)
+		}
 
 		// Check ground state
-		info.OnGround = w.isGrounded(activeBodies[i], info, velY*float32(dt))
+		info.ground, info.onOneWay = w.isGrounded(activeBodies[i], info, velY*float32(dt))
+		info.OnGround = info.ground != nil
 
 		// Update coyote time tracking
 		if info.OnGround {
@@ -130,11 +315,13 @@ func (w *World) preupdate(dt float64, activeBodies []Collider) {
 			info.timeSinceLeftGround += float32(dt)
 		}
 
-		// Apply vertical velocity only when airborne
-		if !info.OnGround {
+		// Apply vertical velocity only when airborne, or when buoyancy lifts the body off the ground
+		if !info.OnGround || (info.InWater() && velY < 0) {
 			info.Velocity[1] = velY
 		}
 
+		w.applyWaterDrag(info)
+
 		// Zero out negligible velocities
 		if math.Abs(float64(info.Velocity[0])) < MinimumVelocityThreshold {
 			info.Velocity[0] = 0
@@ -154,8 +341,12 @@ func (w *World) preupdate(dt float64, activeBodies []Collider) {
 		info.nextPosition[0] = x + info.Velocity[0]*float32(dt)
 		info.nextPosition[1] = y + info.Velocity[1]*float32(dt)
 
-		// Apply horizontal damping
-		info.Velocity[0] *= VelocityDamping
+		// Apply ground friction, or horizontal damping while airborne
+		if ground := info.Ground(); ground != nil {
+			info.Velocity[0] *= 1 - combineFriction(info.Material, ground.Info().Material)
+		} else {
+			info.Velocity[0] *= info.Body.damping(&w.config)
+		}
 	}
 }
 
@@ -167,19 +358,52 @@ func (w *World) postupdate(activeBodies []Collider) {
 		nX, nY := info.nextPosition[0], info.nextPosition[1]
 
 		if nX == x && nY == y {
+			w.updateRest(info)
 			continue
 		}
 
-		info.prevPosition[0] = x
-		info.prevPosition[1] = y
+		info.restSteps = 0
+		w.savePrevious(info, x, y)
 
 		activeBodies[i].SetPosition(nX, nY)
 
-		w.bodyGrid.Remove(activeBodies[i])
-		w.insert(activeBodies[i], w.bodyGrid)
+		w.bodyPhase.Update(activeBodies[i])
+
+		// Moving bodies wake any sleeping bodies they touch
+		w.wakeBodies(activeBodies[i].AABB())
+	}
+}
+
+// updateRest counts the steps a body has rested on the ground and puts it to sleep once it has rested long enough.
+func (w *World) updateRest(info *ColliderInfo) {
+	if w.config.SleepSteps <= 0 || info.Body.AlwaysSimulate || !info.OnGround {
+		info.restSteps = 0
+		return
+	}
+
+	info.restSteps++
+	if info.restSteps >= w.config.SleepSteps {
+		info.sleeping = true
+		info.Velocity = [2]float32{}
+	}
+}
+
+// wakeBodies wakes every sleeping body within the given bounds.
+func (w *World) wakeBodies(minX, minY, maxX, maxY float32) {
+	bodies := w.bodyPhase.Query(minX, minY, maxX, maxY)
+	for i := range bodies {
+		if info := bodies[i].Info(); info.sleeping {
+			info.Wake()
+		}
 	}
 }
 
+// wakeBodiesOn wakes every sleeping body overlapping or resting on a collider.
+func (w *World) wakeBodiesOn(collider Collider) {
+	minX, minY, maxX, maxY := collider.AABB()
+	w.wakeBodies(minX, minY-w.config.GroundCheckDistance, maxX, maxY)
+}
+
 func (w *World) handleCollisions(activeBodies []Collider) {
 	for i := range activeBodies {
 		info := activeBodies[i].Info()
@@ -191,7 +415,7 @@ func (w *World) handleCollisions(activeBodies []Collider) {
 
 		// ========== Check static collisions ==========
 
-		others := w.staticGrid.Query(activeBodies[i].AABB())
+		others := w.querySolids(activeBodies[i].AABB())
 		for j := range others {
 			otherInfo := others[j].Info()
 
@@ -203,7 +427,11 @@ func (w *World) handleCollisions(activeBodies []Collider) {
 				continue
 			}
 
-			if contact, overlaps := CheckOverlap(activeBodies[i], others[j]); overlaps {
+			contact, overlaps := w.checkOverlap(activeBodies[i], others[j])
+			if overlaps && otherInfo.IsOneWay() {
+				contact, overlaps = w.oneWayContact(activeBodies[i], info, others[j], contact, w.previousBottom(activeBodies[i], info))
+			}
+			if overlaps {
 				info.collisions = append(info.collisions, contact)
 			}
 		}
@@ -216,19 +444,24 @@ func (w *World) handleCollisions(activeBodies []Collider) {
 	}
 }
 
-func (w *World) isGrounded(collider Collider, info *ColliderInfo, travelled float32) bool {
+func (w *World) isGrounded(collider Collider, info *ColliderInfo, travelled float32) (ground Collider, oneWay bool) {
 	minX, minY, maxX, maxY := collider.AABB()
 	centerX := (minX + maxX) / 2
-	queryDistance := max(travelled, GroundCheckDistance)
+	queryDistance := max(travelled, w.config.GroundCheckDistance)
 
-	others := w.staticGrid.Query(minX, minY, maxX, maxY+queryDistance)
+	others := w.querySolids(minX, minY, maxX, maxY+queryDistance)
 	for _, other := range others {
 		otherInfo := other.Info()
 		if otherInfo.Mode == CollisionModeIgnore || info.id == otherInfo.id {
 			continue
 		}
 
-		if !ShouldCollide(info.Layer, otherInfo.Layer) || !otherInfo.IsFloor() {
+		if !ShouldCollide(info.Layer, otherInfo.Layer) || !(otherInfo.IsFloor() || otherInfo.IsOneWay()) {
+			continue
+		}
+
+		// One-way platforms only support bodies resting on or falling onto them
+		if otherInfo.IsOneWay() && (info.IsDroppingThrough() || info.Velocity[1] < 0) {
 			continue
 		}
 
@@ -244,33 +477,237 @@ func (w *World) isGrounded(collider Collider, info *ColliderInfo, travelled floa
 
 		case *TriangleCollider:
 			// Check if center point is within triangle bounds
-			oMinX, _, oMaxX, _ := o.AABB()
+			oMinX, oMinY, oMaxX, _ := o.AABB()
 			if centerX < oMinX || centerX > oMaxX {
 				continue
 			}
+
+			// Ceiling slopes are stood on by their flat top
+			if o.SlopeNormal()[1] > 0 {
+				surfaceY = oMinY
+				break
+			}
+
+			// Slopes too steep to rest on are slid down instead
+			if !walkable(o.SlopeNormal(), w.config.MaxSlopeAngle) {
+				continue
+			}
+
 			y, found := geom.FindTriangleSurfaceAt(centerX, &o.Triangle)
 			if !found {
 				continue
 			}
 			surfaceY = y
 
+		case *PolygonCollider:
+			oMinX, _, oMaxX, _ := o.AABB()
+			if centerX < oMinX || centerX > oMaxX {
+				continue
+			}
+			// Faces too steep to rest on are slid down instead
+			if normal, _ := o.SurfaceNormalAt(centerX); !walkable(normal, w.config.MaxSlopeAngle) {
+				continue
+			}
+			y, found := o.SurfaceAt(centerX)
+			if !found {
+				continue
+			}
+			surfaceY = y
+
+		case *CircleCollider:
+			// The sides of a circle are too steep to rest on past the max slope angle
+			if normal, _ := o.SurfaceNormalAt(centerX); !walkable(normal, w.config.MaxSlopeAngle) {
+				continue
+			}
+			y, found := o.SurfaceAt(centerX)
+			if !found {
+				continue
+			}
+			surfaceY = y
+
+		case *CapsuleCollider:
+			// Edges too steep to rest on are slid down instead
+			start, end := o.Segment()
+			if normal := geom.ComputeSlopeNormal(start, end); !walkable([2]float32{normal[0], -geom.Abs(normal[1])}, w.config.MaxSlopeAngle) {
+				continue
+			}
+			// Bodies rest on an edge by the corner under its highest point
+			oMinX, _, oMaxX, _ := o.AABB()
+			if maxX < oMinX || minX > oMaxX {
+				continue
+			}
+			y1, found1 := o.SurfaceAt(max(minX, oMinX))
+			y2, found2 := o.SurfaceAt(min(maxX, oMaxX))
+			switch {
+			case found1 && found2:
+				surfaceY = min(y1, y2)
+			case found1:
+				surfaceY = y1
+			case found2:
+				surfaceY = y2
+			default:
+				continue
+			}
+
 		default:
 			continue
 		}
 
 		// Check if within ground detection range
 		distance := surfaceY - maxY
-		if distance >= -GroundCheckTolerance && distance <= queryDistance {
-			return true
+		if distance >= -w.config.GroundCheckTolerance && distance <= queryDistance {
+			if !otherInfo.IsOneWay() {
+				return other, false
+			}
+			// Keep looking in case the body is also on solid ground
+			ground, oneWay = other, true
+		}
+	}
+	return ground, oneWay
+}
+
+// querySolids returns the static and kinematic colliders within the given bounds.
+// The returned slice is reused between calls.
+func (w *World) querySolids(minX, minY, maxX, maxY float32) []Collider {
+	w.solids = append(w.solids[:0], w.staticPhase.Query(minX, minY, maxX, maxY)...)
+	w.solids = append(w.solids, w.kinematicPhase.Query(minX, minY, maxX, maxY)...)
+	if w.collectStats {
+		w.step.CandidatePairs += len(w.solids)
+	}
+	return sortByID(w.solids)
+}
+
+// sortByID sorts colliders by ID in place so that grid queries resolve in a deterministic order.
+func sortByID(colliders []Collider) []Collider {
+	slices.SortFunc(colliders, func(a, b Collider) int {
+		return cmp.Compare(a.Info().id, b.Info().id)
+	})
+	return colliders
+}
+
+// updateKinematics advances kinematic colliders along their paths or velocities.
+// Bodies standing on a kinematic collider are carried with it, and bodies in its way are pushed out.
+func (w *World) updateKinematics(dt float64) {
+	for _, kinematic := range w.kinematics {
+		info := kinematic.Info()
+		x, y := kinematic.Position()
+
+		if info.Path != nil {
+			info.Velocity[0], info.Velocity[1] = info.Path.velocity(x, y, float32(dt))
+		}
+
+		dx := info.Velocity[0] * float32(dt)
+		dy := info.Velocity[1] * float32(dt)
+		if dx == 0 && dy == 0 {
+			continue
+		}
+
+		// Find riders before the platform moves away from them
+		minX, minY, maxX, maxY := kinematic.AABB()
+		riders := sortByID(w.bodyPhase.Query(minX, minY-w.config.GroundCheckDistance, maxX, maxY))
+
+		w.savePrevious(info, x, y)
+		kinematic.SetPosition(x+dx, y+dy)
+		w.kinematicPhase.Update(kinematic)
+
+		for i := range riders {
+			if ground := riders[i].Info().Ground(); ground != nil && ground.Equals(kinematic) {
+				riders[i].Info().Wake()
+				w.moveBody(riders[i], dx, dy)
+			}
+		}
+
+		if !info.IsOneWay() {
+			w.pushBodies(kinematic, info)
+		}
+	}
+}
+
+// pushBodies moves dynamic bodies out of a kinematic collider that has moved into them.
+func (w *World) pushBodies(kinematic Collider, info *ColliderInfo) {
+	bodies := sortByID(w.bodyPhase.Query(kinematic.AABB()))
+	for i := range bodies {
+		bodyInfo := bodies[i].Info()
+		if (bodyInfo.State != ColliderStateDynamic && bodyInfo.State != ColliderStateCharacter) || bodyInfo.Mode == CollisionModeIgnore {
+			continue
+		}
+
+		if !ShouldCollide(bodyInfo.Layer, info.Layer) {
+			continue
+		}
+
+		contact, overlaps := w.checkOverlap(bodies[i], kinematic)
+		if !overlaps {
+			continue
+		}
+
+		bodyInfo.Wake()
+		w.moveBody(bodies[i], contact.Normal[0]*contact.Depth, contact.Normal[1]*contact.Depth)
+
+		// Remove velocity moving into the kinematic collider
+		dotProduct := bodyInfo.Velocity[0]*contact.Normal[0] + bodyInfo.Velocity[1]*contact.Normal[1]
+		if dotProduct < 0 {
+			bodyInfo.Velocity[0] -= contact.Normal[0] * dotProduct
+			bodyInfo.Velocity[1] -= contact.Normal[1] * dotProduct
+		}
+	}
+}
+
+func (w *World) moveBody(body Collider, dx, dy float32) {
+	x, y := body.Position()
+	w.savePrevious(body.Info(), x, y)
+	body.SetPosition(x+dx, y+dy)
+
+	w.bodyPhase.Update(body)
+}
+
+// previousBottom returns the bottom of a body before the movement of this step.
+func (w *World) previousBottom(body Collider, info *ColliderInfo) float32 {
+	_, _, _, maxY := body.AABB()
+	_, y := body.Position()
+	return maxY - (info.nextPosition[1] - y)
+}
+
+// oneWayContact filters a contact against a one-way platform.
+// The contact is kept only if the bottom of the body was above the platform surface before it moved
+// and the body is not moving upward or dropping through.
+func (w *World) oneWayContact(body Collider, info *ColliderInfo, platform Collider, contact Collision, prevBottom float32) (Collision, bool) {
+	if info.IsDroppingThrough() || info.Velocity[1] < 0 {
+		return contact, false
+	}
+
+	minX, _, maxX, maxY := body.AABB()
+
+	switch p := platform.(type) {
+	case *BoxCollider:
+		_, top, _, _ := p.AABB()
+		if prevBottom > top+w.config.GroundCheckTolerance {
+			return contact, false
 		}
+		// Always push the body up onto the platform surface
+		contact.Normal = [2]float32{0, -1}
+		contact.Depth = maxY - top
+
+	case *TriangleCollider:
+		top, found := geom.FindTriangleSurfaceAt((minX+maxX)*0.5, &p.Triangle)
+		if !found || contact.Normal[1] >= 0 || prevBottom > top+w.config.GroundCheckTolerance {
+			return contact, false
+		}
+
+	default:
+		return contact, false
 	}
-	return false
+
+	return contact, true
 }
 
 func (w *World) resolveStaticCollisions(info *ColliderInfo) {
 	if len(info.collisions) == 0 {
 		return
 	}
+	if w.collectStats {
+		w.step.ResolutionIterations++
+	}
 
 	var horizontal *Collision
 	var vertical *Collision
@@ -279,20 +716,22 @@ func (w *World) resolveStaticCollisions(info *ColliderInfo) {
 	for i := range info.collisions {
 		contact := &info.collisions[i]
 
-		switch contact.other.(type) {
-		case *TriangleCollider:
+		normalX := math.Abs(float64(contact.Normal[0]))
+		normalY := math.Abs(float64(contact.Normal[1]))
+
+		switch {
+		case normalX > float64(Epsilon) && normalY > float64(Epsilon):
+			// Angled or curved surfaces resolve along their normal like slopes
 			if slope == nil || contact.Depth > slope.Depth {
 				slope = contact
 			}
+		case normalY > normalX:
+			if vertical == nil || contact.Depth > vertical.Depth {
+				vertical = contact
+			}
 		default:
-			if math.Abs(float64(contact.Normal[1])) > math.Abs(float64(contact.Normal[0])) {
-				if vertical == nil || contact.Depth > vertical.Depth {
-					vertical = contact
-				}
-			} else {
-				if horizontal == nil || contact.Depth > horizontal.Depth {
-					horizontal = contact
-				}
+			if horizontal == nil || contact.Depth > horizontal.Depth {
+				horizontal = contact
 			}
 		}
 	}
@@ -320,11 +759,19 @@ func (w *World) resolveStaticSlopeCollision(info *ColliderInfo, col *Collision)
 	info.nextPosition[0] += col.Normal[0] * col.Depth
 	info.nextPosition[1] += col.Normal[1] * col.Depth
 
-	if col.Normal[1] < 0 {
-		// Colliding with ceiling - stop upward velocity
+	// Bounce off the slope if the surfaces are bouncy and the impact is fast enough
+	restitution := combineRestitution(info.Material, col.other.Info().Material)
+	if impact := info.Velocity[0]*col.Normal[0] + info.Velocity[1]*col.Normal[1]; impact < 0 && bounce(impact, restitution) != 0 {
+		info.Velocity[0] -= (1 + restitution) * col.Normal[0] * impact
+		info.Velocity[1] -= (1 + restitution) * col.Normal[1] * impact
+		return
+	}
+
+	if walkable(col.Normal, w.config.MaxSlopeAngle) {
+		// Landing on a walkable slope - stop vertical velocity
 		info.Velocity[1] = 0
 	} else {
-		// Redirect velocity along slope surface
+		// Steep and ceiling slopes redirect velocity along the slope surface
 		dotProduct := info.Velocity[0]*col.Normal[0] + info.Velocity[1]*col.Normal[1]
 		if dotProduct < 0 {
 			info.Velocity[0] -= col.Normal[0] * dotProduct
@@ -337,9 +784,9 @@ func (w *World) resolveStaticVerticalCollision(info *ColliderInfo, col *Collisio
 	// Move out of collision vertically
 	info.nextPosition[1] += col.Normal[1] * col.Depth
 
-	// Stop velocity if moving into the collision
+	// Stop or bounce velocity if moving into the collision
 	if (col.Normal[1] < 0 && info.Velocity[1] > 0) || (col.Normal[1] > 0 && info.Velocity[1] < 0) {
-		info.Velocity[1] = 0
+		info.Velocity[1] = bounce(info.Velocity[1], combineRestitution(info.Material, col.other.Info().Material))
 	}
 }
 
@@ -347,8 +794,8 @@ func (w *World) resolveStaticHorizontalCollision(info *ColliderInfo, col *Collis
 	// Move out of collision horizontally
 	info.nextPosition[0] += col.Normal[0] * col.Depth
 
-	// Stop velocity if moving into the collision
+	// Stop or bounce velocity if moving into the collision
 	if (col.Normal[0] < 0 && info.Velocity[0] > 0) || (col.Normal[0] > 0 && info.Velocity[0] < 0) {
-		info.Velocity[0] = 0
+		info.Velocity[0] = bounce(info.Velocity[0], combineRestitution(info.Material, col.other.Info().Material))
 	}
 }
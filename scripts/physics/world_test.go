@@ -0,0 +1,148 @@
+package physics
+
+import (
+	"testing"
+
+	"github.com/adm87/deepdown/scripts/geom"
+)
+
+func addBody(w *World, x, y, width, height float32) *BoxCollider {
+	body := w.Pool().GetBoxCollider(x, y, width, height)
+	body.State = ColliderStateDynamic
+	w.AddCollider(body)
+	return body
+}
+
+func surfaceAt(c Collider, x float32) (float32, bool) {
+	switch o := c.(type) {
+	case *PolygonCollider:
+		return o.SurfaceAt(x)
+	case *CircleCollider:
+		return o.SurfaceAt(x)
+	}
+	return 0, false
+}
+
+func TestGroundIsWalkable(t *testing.T) {
+	floor := CollisionRoleFloor | CollisionRoleWall
+
+	tests := []struct {
+		name     string
+		ground   func(w *World) Collider
+		bodyX    float32 // Center of the body, resting on the surface
+		walkable bool
+	}{
+		{
+			name: "shallow polygon face",
+			ground: func(w *World) Collider {
+				return w.Pool().GetPolygonCollider(0, 100, [][2]float32{{0, 40}, {90, 40}, {80, 0}})
+			},
+			bodyX:    40,
+			walkable: true,
+		},
+		{
+			name: "steep polygon face",
+			ground: func(w *World) Collider {
+				return w.Pool().GetPolygonCollider(0, 100, [][2]float32{{0, 40}, {34, 40}, {28, 0}})
+			},
+			bodyX:    14,
+			walkable: false,
+		},
+		{
+			name: "polygon top between steep faces",
+			ground: func(w *World) Collider {
+				return w.Pool().GetPolygonCollider(0, 100, [][2]float32{{0, 40}, {40, 40}, {30, 0}, {10, 0}})
+			},
+			bodyX:    20,
+			walkable: true,
+		},
+		{
+			name: "top of circle",
+			ground: func(w *World) Collider {
+				return w.Pool().GetCircleCollider(100, 120, 20)
+			},
+			bodyX:    100,
+			walkable: true,
+		},
+		{
+			name: "side of circle",
+			ground: func(w *World) Collider {
+				return w.Pool().GetCircleCollider(100, 120, 20)
+			},
+			bodyX:    116,
+			walkable: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld()
+			ground := addSolid(w, tt.ground(w), floor)
+
+			surfaceY, found := surfaceAt(ground, tt.bodyX)
+			if !found {
+				t.Fatalf("no surface at x = %.2f", tt.bodyX)
+			}
+			body := addBody(w, tt.bodyX-2, surfaceY-4, 4, 4)
+
+			got, _ := w.isGrounded(body, body.Info(), 0)
+			if (got != nil) != tt.walkable {
+				t.Errorf("grounded = %v, want %v", got != nil, tt.walkable)
+			}
+		})
+	}
+}
+
+func TestDecomposedPolygonSlopes(t *testing.T) {
+	// A concave hill with a steep left face, a flat top and a step on its right, as drawn in Tiled
+	hill := [][2]float32{{0, 40}, {14, 0}, {30, 0}, {30, 20}, {50, 20}, {50, 40}}
+
+	tests := []struct {
+		name  string
+		bodyX float32 // Center of the body, resting on the surface
+		rests bool
+	}{
+		{name: "steep face", bodyX: 6, rests: false},
+		{name: "top", bodyX: 22, rests: true},
+		{name: "step", bodyX: 40, rests: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld()
+
+			pieces := geom.DecomposePolygon(hill)
+			if len(pieces) < 2 {
+				t.Fatalf("concave polygon decomposed into %d pieces", len(pieces))
+			}
+
+			surfaceY, found := float32(0), false
+			for _, piece := range pieces {
+				ground := addSolid(w, w.Pool().GetPolygonCollider(0, 100, piece), CollisionRoleFloor|CollisionRoleWall)
+				if y, ok := surfaceAt(ground, tt.bodyX); ok && (!found || y < surfaceY) {
+					surfaceY, found = y, true
+				}
+			}
+			if !found {
+				t.Fatalf("no surface at x = %.2f", tt.bodyX)
+			}
+
+			body := addBody(w, tt.bodyX-2, surfaceY-4, 4, 4)
+			if got, _ := w.isGrounded(body, body.Info(), 0); (got != nil) != tt.rests {
+				t.Fatalf("grounded = %v, want %v", got != nil, tt.rests)
+			}
+
+			for range 120 {
+				stepWorld(w)
+			}
+
+			moved := body.X + 2 - tt.bodyX
+			if tt.rests && (!body.OnGround || !near(moved, 0, 0.5)) {
+				t.Errorf("body moved %.2f, grounded %v, want resting", moved, body.OnGround)
+			}
+			if !tt.rests && (body.Y < surfaceY+20 || near(moved, 0, 2)) {
+				t.Errorf("body moved %.2f to y = %.2f, want sliding down the face", moved, body.Y)
+			}
+		})
+	}
+}